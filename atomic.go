@@ -0,0 +1,163 @@
+package properties
+
+import (
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// writeFileAtomic writes the file by a temporary file in the same directory renamed over it,
+// so the file is either the old one or the new one even if the process crashes.
+//
+// The mode and the ownership(if permitted) of the existing file are kept,
+// and the existing file is kept as the backup if backups > 0, see rotateBackups.
+// If the file is a symbolic link, its target is written and the link is kept.
+func writeFileAtomic(file string, backups int, write func(io.Writer) error) error {
+	file, err := resolveSymlinks(file)
+	if err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	perm := os.FileMode(0666)
+	if fi != nil {
+		perm = fi.Mode().Perm()
+	}
+
+	tmp, err := createTemp(file, perm)
+	if err != nil {
+		return err
+	}
+
+	if err = writeTemp(tmp, fi, write); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if fi != nil && backups > 0 {
+		if err = rotateBackups(file, backups); err != nil {
+			os.Remove(tmp.Name())
+			return err
+		}
+	}
+
+	if err = os.Rename(tmp.Name(), file); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return syncDir(filepath.Dir(file))
+}
+
+// resolveSymlinks gives the target of the file if it's a symbolic link, or the file itself if it does not exist.
+func resolveSymlinks(file string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(file)
+	if os.IsNotExist(err) {
+		return file, nil
+	}
+
+	return resolved, err
+}
+
+// createTemp creates a new temporary file besides the file.
+func createTemp(file string, perm os.FileMode) (*os.File, error) {
+	dir, base := filepath.Split(file)
+	r := rand.New(rand.NewSource(time.Now().UnixNano())) // nolint gosec
+
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, "."+base+"."+strconv.Itoa(r.Int())+".tmp")
+
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+
+		return f, err
+	}
+}
+
+// writeTemp writes and syncs the temporary file, then closes it.
+func writeTemp(tmp *os.File, fi os.FileInfo, write func(io.Writer) error) error {
+	err := write(tmp)
+
+	if err == nil && fi != nil {
+		//  umask可能去掉了部分权限
+		if err = tmp.Chmod(fi.Mode().Perm()); err == nil {
+			chown(tmp, fi)
+		}
+	}
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// rotateBackups keeps the file as file.bak, and the former backups as file.bak.1 ... file.bak.{backups-1}.
+func rotateBackups(file string, backups int) error {
+	name := func(i int) string {
+		if i == 0 {
+			return file + ".bak"
+		}
+
+		return file + ".bak." + strconv.Itoa(i)
+	}
+
+	if err := os.Remove(name(backups - 1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for i := backups - 2; i >= 0; i-- {
+		if err := os.Rename(name(i), name(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	//  硬链接使原文件在替换之前一直保持完整
+	if err := os.Link(file, name(0)); err == nil {
+		return nil
+	}
+
+	return copyFile(file, name(0))
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+
+	defer in.Close()
+
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.Copy(out, in); err == nil {
+		err = out.Sync()
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,89 @@
+package properties
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportFileAtomic(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.properties": "a=1\n"})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	file := filepath.Join(dir, "app.properties")
+	that.Nil(os.Chmod(file, 0600))
+
+	doc, _ := LoadFile(file)
+
+	for _, v := range []string{"2", "3", "4", "5"} {
+		doc.Set("a", v)
+		that.Nil(doc.ExportFile(file, WithBackups(3)))
+	}
+
+	read := func(name string) string {
+		b, _ := ioutil.ReadFile(filepath.Join(dir, name))
+		return string(b)
+	}
+
+	that.Equal("a=5\n", read("app.properties"))
+	that.Equal("a=4\n", read("app.properties.bak"))
+	that.Equal("a=3\n", read("app.properties.bak.1"))
+	that.Equal("a=2\n", read("app.properties.bak.2"))
+
+	files, _ := ioutil.ReadDir(dir)
+	that.Len(files, 4, "no more backups and no temporary files left")
+
+	fi, _ := os.Stat(file)
+	that.Equal(os.FileMode(0600), fi.Mode().Perm())
+}
+
+func TestExportFileFailed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.properties": "a=1\n"})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	file := filepath.Join(dir, "app.properties")
+
+	doc, _ := LoadFile(file)
+	doc.Set("a", "中文")
+
+	that.NotNil(doc.ExportFile(file, WithOutputEncoding(EncodingISO88591)))
+
+	b, _ := ioutil.ReadFile(file)
+	that.Equal("a=1\n", string(b), "the file is not touched")
+
+	files, _ := ioutil.ReadDir(dir)
+	that.Len(files, 1, "the temporary file is removed")
+
+	that.NotNil(doc.ExportFile(filepath.Join(dir, "missing", "app.properties")))
+}
+
+func TestExportFileSymlink(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"data/app.properties": "a=1\n"})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	link := filepath.Join(dir, "app.properties")
+
+	if err := os.Symlink(filepath.Join("data", "app.properties"), link); err != nil {
+		t.Skip("symlink not supported:", err)
+	}
+
+	doc, _ := LoadFile(link)
+	doc.Set("a", "2")
+	that.Nil(doc.ExportFile(link, WithBackups(1)))
+
+	fi, err := os.Lstat(link)
+	that.Nil(err)
+	that.True(fi.Mode()&os.ModeSymlink != 0, "the link is kept")
+
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "data", "app.properties"))
+	that.Equal("a=2\n", string(b))
+
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "data", "app.properties.bak"))
+	that.Equal("a=1\n", string(b), "the backup is besides the target")
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package properties
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown keeps the ownership of the file, the error is ignored since only the root can give the file away.
+func chown(f *os.File, fi os.FileInfo) {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		_ = f.Chown(int(st.Uid), int(st.Gid))
+	}
+}
+
+// syncDir syncs the directory to persist the renaming.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	err = d.Sync()
+
+	if closeErr := d.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package properties
+
+import (
+	"os"
+)
+
+// chown is not supported on windows.
+func chown(f *os.File, fi os.FileInfo) {}
+
+// syncDir is not supported on windows, the renaming is persisted by the file system.
+func syncDir(dir string) error {
+	return nil
+}
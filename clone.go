@@ -0,0 +1,59 @@
+package properties
+
+import (
+	"container/list"
+)
+
+// Clone copies the document deeply, include the comments and the layout of the lines.
+//
+// Copying the Doc value shares the lines with the original, use Clone or Snapshot instead.
+func (p Doc) Clone() *Doc {
+	c := p
+	c.shared = false
+	c.lines = list.New()
+	c.props = make(map[string]*list.Element, len(p.props))
+
+	elems := make(map[*list.Element]*list.Element, len(p.props))
+
+	for e := p.lines.Front(); e != nil; e = e.Next() {
+		l := *e.Value.(*line)
+		elems[e] = c.lines.PushBack(&l)
+	}
+
+	for key, e := range p.props {
+		c.props[key] = elems[e]
+	}
+
+	if p.profiles != nil {
+		c.profiles = make(map[string]string, len(p.profiles))
+
+		for file, profile := range p.profiles {
+			c.profiles[file] = profile
+		}
+	}
+
+	return &c
+}
+
+// Snapshot gives a view of the document at this moment, which is not affected by the later edits.
+//
+// The snapshot is cheap since the lines are shared until either of the documents is modified,
+// then the modified one copies the lines first(copy-on-write).
+// So the snapshot can be used as the "before" document of Diff safely.
+// Snapshot modifies the sharing state of the doc, so it should not be called concurrently with the other methods.
+func (p *Doc) Snapshot() *Doc {
+	p.shared = true
+	s := *p
+
+	return &s
+}
+
+// beforeWrite copies the lines shared with the snapshots before modifying them.
+func (p *Doc) beforeWrite() {
+	if !p.shared {
+		return
+	}
+
+	c := p.Clone()
+	p.lines, p.props, p.profiles, p.shared = c.lines, c.props, c.profiles, false
+}
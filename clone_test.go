@@ -0,0 +1,56 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClone(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# comment\n  a  :  1  \r\nb=2\nb=3\n"
+	doc, _ := LoadString(text)
+
+	c := doc.Clone()
+	that.Equal(text, c.String())
+
+	c.Set("a", "10")
+	c.Set("b", "4")
+	c.Comment("a", "new")
+
+	that.Equal(text, doc.String())
+	that.Equal("# comment\n#new\n  a  :  10  \r\nb=4\n", c.String())
+
+	pos, _ := c.Position("a")
+	that.Equal(2, pos.Line)
+}
+
+func TestSnapshot(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadString("# comment\na=1\nb=2\n")
+	before := doc.Snapshot()
+	same := doc.Snapshot()
+
+	doc.Set("a", "10")
+	doc.Del("b")
+	doc.Uncomment("a")
+
+	that.Equal("# comment\na=1\nb=2\n", before.String())
+	that.Equal("a=10\n", doc.String())
+
+	var events []DiffEvent
+
+	Diff(before, doc, func(e DiffEvent) { events = append(events, e) })
+	that.Equal([]DiffEvent{
+		{ChangeType: Modified, Key: "a", LeftValue: "1", RightValue: "10"},
+		{ChangeType: Removed, Key: "b", LeftValue: "2"},
+	}, events)
+
+	//  快照之间也互不影响
+	before.Set("c", "3")
+	that.Equal("# comment\na=1\nb=2\n", same.String())
+	that.Equal("# comment\na=1\nb=2\nc=3\n", before.String())
+	that.Equal("a=10\n", doc.String())
+}
@@ -2,6 +2,7 @@ package properties
 
 import (
 	"bufio"
+	"container/list"
 	"strings"
 )
 
@@ -9,6 +10,8 @@ import (
 //
 // Return false if the special line is not exist.
 func (p *Doc) Comment(key, comments string) bool {
+	p.beforeWrite()
+
 	e, ok := p.props[key]
 	if !ok {
 		return false
@@ -33,22 +36,28 @@ func (p *Doc) Comment(key, comments string) bool {
 //
 // Return false if the special line is not exist.
 func (p *Doc) Uncomment(key string) bool {
+	p.beforeWrite()
+
 	e, ok := p.props[key]
 	if !ok {
 		return false
 	}
 
+	p.uncommentElem(e)
+
+	return true
+}
+
+// uncommentElem removes the comments right before the element.
+func (p *Doc) uncommentElem(e *list.Element) {
 	for i := e.Prev(); nil != i; {
 		del := i
 		i = i.Prev()
 
-		typo := del.Value.(*line).typo
-		if isProperty(typo) || typo == ' ' {
+		if !isComment(del.Value.(*line).typo) {
 			break
 		}
 
 		p.lines.Remove(del)
 	}
-
-	return true
 }
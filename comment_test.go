@@ -18,7 +18,7 @@ func Test_Comment_Uncomment(t *testing.T) {
 	err := doc.Save(buf)
 	expect(t, "格式化成功", nil == err)
 
-	exp1 := "#This is a \n#comment \n#for a\nkey1=1\nkey 2=2\n"
+	exp1 := "#This is a \n#comment \n#for a\nkey1=1\nkey 2 = 2"
 	expect(t, "对已经存在的项进行注释", exp1 == buf.String())
 
 	doc.Comment("key 2", "")
@@ -27,7 +27,7 @@ func Test_Comment_Uncomment(t *testing.T) {
 	err = doc.Save(buf)
 	expect(t, "格式化成功", nil == err)
 
-	exp2 := "#This is a \n#comment \n#for a\nkey1=1\n#\nkey 2=2\n"
+	exp2 := "#This is a \n#comment \n#for a\nkey1=1\n#\nkey 2 = 2"
 	expect(t, "对已经存在的项进行注释", exp2 == buf.String())
 
 	exist = doc.Uncomment("key1")
@@ -37,7 +37,7 @@ func Test_Comment_Uncomment(t *testing.T) {
 	err = doc.Save(buf)
 	expect(t, "格式化成功", nil == err)
 
-	exp3 := "key1=1\n#\nkey 2=2\n"
+	exp3 := "key1=1\n#\nkey 2 = 2"
 	expect(t, "对已经存在的项进行注释", exp3 == buf.String())
 
 	exist = doc.Uncomment("key 2")
@@ -48,7 +48,7 @@ func Test_Comment_Uncomment(t *testing.T) {
 	err = doc.Save(buf)
 	expect(t, "格式化成功", nil == err)
 
-	exp4 := "key1=1\nkey 2=2\n"
+	exp4 := "key1=1\nkey 2 = 2"
 	expect(t, "对已经存在的项进行注释", exp4 == buf.String())
 
 	exist = doc.Uncomment("NOT-EXIST")
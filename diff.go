@@ -1,5 +1,11 @@
 package properties
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // ChangeType defines the type of chaging.
 type ChangeType int
 
@@ -12,37 +18,257 @@ const (
 	Removed
 	// Same ...
 	Same
+	// Commented means the comments of the key changed, the values of the event are the comments, see DiffOptions.
+	Commented
+	// Moved means the key moved relative to the other keys, see DiffOptions.
+	Moved
 )
 
 // DiffEvent defines ChangeEvent for properties diff
 type DiffEvent struct {
-	ChangeType ChangeType
-	Key        string
-	LeftValue  string
-	RightValue string
+	ChangeType ChangeType `json:"type"`
+	Key        string     `json:"key"`
+	LeftValue  string     `json:"left"`
+	RightValue string     `json:"right"`
+}
+
+// nolint gochecknoglobals
+var changeTypeNames = []string{Modified: "modified", Added: "added", Removed: "removed", Same: "same",
+	Commented: "commented", Moved: "moved"}
+
+// String gives the name of the ChangeType like "modified".
+func (c ChangeType) String() string {
+	if c >= 0 && int(c) < len(changeTypeNames) {
+		return changeTypeNames[c]
+	}
+
+	return "ChangeType(" + strconv.Itoa(int(c)) + ")"
+}
+
+// MarshalText marshals the ChangeType to its name.
+func (c ChangeType) MarshalText() ([]byte, error) {
+	if c < 0 || int(c) >= len(changeTypeNames) {
+		return nil, fmt.Errorf("unknown %s", c)
+	}
+
+	return []byte(c.String()), nil
 }
 
-// Diff diffs l to r.
+// UnmarshalText unmarshals the ChangeType from its name.
+func (c *ChangeType) UnmarshalText(text []byte) error {
+	for i, name := range changeTypeNames {
+		if name == string(text) {
+			*c = ChangeType(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown change type %q", text)
+}
+
+// DiffOptions defines the options of DiffWith.
+type DiffOptions struct {
+	// Comments reports the Commented events for the keys whose leading comment lines changed.
+	Comments bool
+	// Moves reports the Moved events for the keys whose order relative to the other keys changed.
+	Moves bool
+
+	// Ignore ignores the keys matching any of the glob patterns like "*.password", see path.Match.
+	Ignore []string
+	// IgnoreCase compares the values case-insensitively.
+	IgnoreCase bool
+	// TrimSpace compares the values without the leading and trailing white spaces.
+	TrimSpace bool
+	// Semantic compares the numbers and the durations by their values, like 1.0 == 1 and 10s == 10000ms.
+	Semantic bool
+	// Normalizers normalize the values in order before comparing.
+	Normalizers []func(key, value string) string
+	// Secrets masks the values of the keys matching any of the glob patterns in the events.
+	Secrets []string
+}
+
+// Diff diffs l to r, see DiffWith.
 func Diff(l, r *Doc, f func(DiffEvent)) {
-	lm := make(map[string]string)
-
-	l.Foreach(func(v, k string) bool { lm[k] = v; return true })
-	r.Foreach(func(v, k string) bool {
-		if lv, ok := lm[k]; ok {
-			typ := Same
-			if v != lv {
-				typ = Modified
-			}
-			f(DiffEvent{ChangeType: typ, Key: k, LeftValue: lv, RightValue: v})
-			delete(lm, k)
-		} else {
-			f(DiffEvent{ChangeType: Added, Key: k, LeftValue: "", RightValue: v})
+	DiffWith(l, r, DiffOptions{}, f)
+}
+
+// DiffWith diffs l to r with the options.
+//
+// The events are in the document order of r, and the Removed events are at their original positions in l,
+// that is right before the event of the next key of l which is still in r.
+// The Commented and Moved events follow the Same or Modified event of their keys.
+func DiffWith(l, r *Doc, opts DiffOptions, f func(DiffEvent)) {
+	lkeys, rkeys := opts.keys(l), opts.keys(r)
+	lset, rset := keySet(lkeys), keySet(rkeys)
+
+	emit := func(e DiffEvent) {
+		if e.ChangeType != Commented && opts.secret(e.Key) {
+			e.LeftValue, e.RightValue = maskValue(e.LeftValue), maskValue(e.RightValue)
+		}
+
+		f(e)
+	}
+
+	removed := make(map[string][]DiffEvent) //  后一个保留的key -> 在它之前删除的key
+	anchor := ""
+
+	for i := len(lkeys) - 1; i >= 0; i-- {
+		k := lkeys[i]
+		if rset[k] {
+			anchor = k
+			continue
+		}
+
+		lv, _ := l.GetRaw(k)
+		removed[anchor] = append([]DiffEvent{{ChangeType: Removed, Key: k, LeftValue: lv}}, removed[anchor]...)
+	}
+
+	var moved map[string]bool
+	if opts.Moves {
+		moved = movedKeys(lkeys, rkeys, lset, rset)
+	}
+
+	for _, k := range rkeys {
+		rv, _ := r.GetRaw(k)
+
+		if !lset[k] {
+			emit(DiffEvent{ChangeType: Added, Key: k, RightValue: rv})
+			continue
+		}
+
+		for _, e := range removed[k] {
+			emit(e)
+		}
+
+		lv, _ := l.GetRaw(k)
+
+		typ := Same
+		if !opts.equal(k, lv, rv) {
+			typ = Modified
+		}
+
+		emit(DiffEvent{ChangeType: typ, Key: k, LeftValue: lv, RightValue: rv})
+
+		if lc, rc := l.comments(k), r.comments(k); opts.Comments && lc != rc {
+			emit(DiffEvent{ChangeType: Commented, Key: k, LeftValue: lc, RightValue: rc})
+		}
+
+		if moved[k] {
+			emit(DiffEvent{ChangeType: Moved, Key: k, LeftValue: lv, RightValue: rv})
+		}
+	}
+
+	for _, e := range removed[""] {
+		emit(e)
+	}
+}
+
+// keys gives the keys in the order of their first appearance.
+func (p Doc) keys() []string {
+	var keys []string
+
+	visited := make(map[string]bool)
+
+	p.Foreach(func(_, k string) bool {
+		if !visited[k] {
+			visited[k] = true
+			keys = append(keys, k)
 		}
 
 		return true
 	})
 
-	for k, v := range lm {
-		f(DiffEvent{ChangeType: Removed, Key: k, LeftValue: v, RightValue: ""})
+	return keys
+}
+
+func keySet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+
+	for _, k := range keys {
+		set[k] = true
 	}
+
+	return set
+}
+
+// comments gives the comment lines right before the effective line of the key.
+func (p Doc) comments(key string) string {
+	var comments []string
+
+	for e := p.props[key].Prev(); e != nil && isComment(e.Value.(*line).typo); e = e.Prev() {
+		comments = append([]string{e.Value.(*line).value}, comments...)
+	}
+
+	return strings.Join(comments, "\n")
+}
+
+// movedKeys gives the common keys out of the longest common subsequence of the key orders.
+func movedKeys(lkeys, rkeys []string, lset, rset map[string]bool) map[string]bool {
+	var common, rcommon []string
+
+	for _, k := range lkeys {
+		if rset[k] {
+			common = append(common, k)
+		}
+	}
+
+	for _, k := range rkeys {
+		if lset[k] {
+			rcommon = append(rcommon, k)
+		}
+	}
+
+	//  lcs[i][j]为common[i:]与rcommon[j:]的最长公共子序列长度
+	n := len(common)
+	lcs := make([][]int, n+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, n+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := n - 1; j >= 0; j-- {
+			if common[i] == rcommon[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	moved := make(map[string]bool)
+
+	for _, k := range common {
+		moved[k] = true
+	}
+
+	for i, j := 0, 0; i < n && j < n; {
+		switch {
+		case common[i] == rcommon[j]:
+			delete(moved, common[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return moved
+}
+
+// changes gives the DiffEvents from l to r except Same.
+func changes(l, r *Doc) []DiffEvent {
+	var events []DiffEvent
+
+	Diff(l, r, func(e DiffEvent) {
+		if e.ChangeType != Same {
+			events = append(events, e)
+		}
+	})
+
+	return events
 }
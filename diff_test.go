@@ -19,7 +19,60 @@ func TestDiff(t *testing.T) {
 	assert.Equal(t, []DiffEvent{
 		{ChangeType: Modified, Key: "k1", LeftValue: "v1", RightValue: "v10"},
 		{ChangeType: Added, Key: "k3", LeftValue: "", RightValue: "v3"},
-		{ChangeType: Same, Key: "k4", LeftValue: "v4", RightValue: "v4"},
 		{ChangeType: Removed, Key: "k2", LeftValue: "v2", RightValue: ""},
+		{ChangeType: Same, Key: "k4", LeftValue: "v4", RightValue: "v4"},
 	}, events)
 }
+
+func diffEvents(l, r string, opts DiffOptions) []DiffEvent {
+	ld, _ := LoadString(l)
+	rd, _ := LoadString(r)
+
+	var events []DiffEvent
+
+	DiffWith(ld, rd, opts, func(e DiffEvent) { events = append(events, e) })
+
+	return events
+}
+
+func TestDiffDeterministic(t *testing.T) {
+	that := assert.New(t)
+
+	l := "a=1\nr1=1\nr2=2\nb=2\nr3=3\nr4=4\n"
+	r := "a=1\nb=2\nc=3\n"
+
+	expected := []DiffEvent{
+		{ChangeType: Same, Key: "a", LeftValue: "1", RightValue: "1"},
+		{ChangeType: Removed, Key: "r1", LeftValue: "1"},
+		{ChangeType: Removed, Key: "r2", LeftValue: "2"},
+		{ChangeType: Same, Key: "b", LeftValue: "2", RightValue: "2"},
+		{ChangeType: Added, Key: "c", RightValue: "3"},
+		{ChangeType: Removed, Key: "r3", LeftValue: "3"},
+		{ChangeType: Removed, Key: "r4", LeftValue: "4"},
+	}
+
+	for i := 0; i < 10; i++ {
+		that.Equal(expected, diffEvents(l, r, DiffOptions{}))
+	}
+}
+
+func TestDiffCommentsAndMoves(t *testing.T) {
+	that := assert.New(t)
+
+	l := "# a\na=1\nb=2\nc=3\nd=4\n"
+	r := "# a1\n# a2\na=1\nc=3\n# b\nb=2\nd=40\n"
+
+	that.Equal([]DiffEvent{
+		{ChangeType: Same, Key: "a", LeftValue: "1", RightValue: "1"},
+		{ChangeType: Commented, Key: "a", LeftValue: "# a", RightValue: "# a1\n# a2"},
+		{ChangeType: Same, Key: "c", LeftValue: "3", RightValue: "3"},
+		{ChangeType: Same, Key: "b", LeftValue: "2", RightValue: "2"},
+		{ChangeType: Commented, Key: "b", LeftValue: "", RightValue: "# b"},
+		{ChangeType: Moved, Key: "b", LeftValue: "2", RightValue: "2"},
+		{ChangeType: Modified, Key: "d", LeftValue: "4", RightValue: "40"},
+	}, diffEvents(l, r, DiffOptions{Comments: true, Moves: true}))
+
+	that.Len(diffEvents(l, r, DiffOptions{}), 4)
+	that.Equal("commented", Commented.String())
+	that.Equal("ChangeType(9)", ChangeType(9).String())
+}
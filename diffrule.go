@@ -0,0 +1,88 @@
+package properties
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maskedValue = "******"
+
+// keys gives the keys of the doc not ignored, in the order of their first appearance.
+func (o DiffOptions) keys(doc *Doc) []string {
+	var keys []string
+
+	for _, k := range doc.keys() {
+		if !matchAny(o.Ignore, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// secret tells whether the value of the key should be masked.
+func (o DiffOptions) secret(key string) bool {
+	return matchAny(o.Secrets, key)
+}
+
+// equal compares the values of the key by the rules.
+func (o DiffOptions) equal(key, l, r string) bool {
+	l, r = o.normalize(key, l), o.normalize(key, r)
+	if l == r {
+		return true
+	}
+
+	if o.IgnoreCase && strings.EqualFold(l, r) {
+		return true
+	}
+
+	return o.Semantic && semanticEqual(l, r)
+}
+
+func (o DiffOptions) normalize(key, value string) string {
+	if o.TrimSpace {
+		value = strings.TrimSpace(value)
+	}
+
+	for _, f := range o.Normalizers {
+		value = f(key, value)
+	}
+
+	return value
+}
+
+// semanticEqual compares the numbers or the durations by their values.
+func semanticEqual(l, r string) bool {
+	if lf, err := strconv.ParseFloat(l, 64); err == nil {
+		rf, err := strconv.ParseFloat(r, 64)
+		return err == nil && lf == rf
+	}
+
+	if ld, err := time.ParseDuration(l); err == nil {
+		rd, err := time.ParseDuration(r)
+		return err == nil && ld == rd
+	}
+
+	return false
+}
+
+func matchAny(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// maskValue masks the value, the empty value is kept to tell the value is set or not.
+func maskValue(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return maskedValue
+}
@@ -0,0 +1,61 @@
+package properties
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRules(t *testing.T) {
+	that := assert.New(t)
+
+	l := "a=on\nb=x\nc=1.0\nd=10s\ne=abc\nf=v1\ntmp.x=1\ndb.password=old\nweb.secret=s\nr=1\n"
+	r := "a=ON\nb=x\nc=1\nd=10000ms\ne=1\nf=V1-snapshot\ntmp.x=2\ndb.password=new\nweb.secret=s\nadded.secret=z\n"
+
+	opts := DiffOptions{
+		Ignore:     []string{"tmp.*"},
+		IgnoreCase: true,
+		TrimSpace:  true,
+		Semantic:   true,
+		Normalizers: []func(key, value string) string{
+			func(_, v string) string { return strings.TrimSuffix(v, "-snapshot") },
+		},
+		Secrets: []string{"*.password", "*.secret"},
+	}
+
+	that.Equal([]DiffEvent{
+		{ChangeType: Same, Key: "a", LeftValue: "on", RightValue: "ON"},
+		{ChangeType: Same, Key: "b", LeftValue: "x", RightValue: "x"},
+		{ChangeType: Same, Key: "c", LeftValue: "1.0", RightValue: "1"},
+		{ChangeType: Same, Key: "d", LeftValue: "10s", RightValue: "10000ms"},
+		{ChangeType: Modified, Key: "e", LeftValue: "abc", RightValue: "1"},
+		{ChangeType: Same, Key: "f", LeftValue: "v1", RightValue: "V1-snapshot"},
+		{ChangeType: Modified, Key: "db.password", LeftValue: "******", RightValue: "******"},
+		{ChangeType: Same, Key: "web.secret", LeftValue: "******", RightValue: "******"},
+		{ChangeType: Added, Key: "added.secret", RightValue: "******"},
+		{ChangeType: Removed, Key: "r", LeftValue: "1"},
+	}, diffEvents(l, r, opts))
+
+	that.Len(changesWith(l, r, DiffOptions{}), 9)
+	that.Len(changesWith(l, r, opts), 4)
+
+	that.True(opts.equal("b", " x ", "x"))
+	that.False(DiffOptions{}.equal("b", " x ", "x"))
+
+	that.False(semanticEqual("1", "1s"))
+	that.False(semanticEqual("1s", "x"))
+	that.False(semanticEqual("x", "x"))
+}
+
+func changesWith(l, r string, opts DiffOptions) []DiffEvent {
+	var events []DiffEvent
+
+	for _, e := range diffEvents(l, r, opts) {
+		if e.ChangeType != Same {
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
@@ -12,24 +12,32 @@ func New() *Doc {
 	return &Doc{
 		lines: list.New(),
 		props: make(map[string]*list.Element),
+		eol:   "\n",
 	}
 }
 
-// Get retrieves the value from Doc.
+// Get retrieves the expanded value from Doc, see Lookup for the expansion.
 //
 // If the line is not exist, the exist is false.
+// If the value can not be expanded, the raw value will be returned.
 func (p Doc) Get(key string) (value string, exist bool) {
-	if e, ok := p.props[key]; ok {
-		return e.Value.(*line).value, ok
+	value, exist, err := p.Lookup(key)
+	if err != nil {
+		return p.GetRaw(key)
 	}
 
-	return "", false
+	return value, exist
 }
 
 // MustGet returns the expanded value for the given key if exists or
 // panics otherwise.
 func (p Doc) MustGet(key string) (value string) {
-	if val, ok := p.Get(key); ok {
+	val, ok, err := p.Lookup(key)
+	if err != nil {
+		panic(err)
+	}
+
+	if ok {
 		return val
 	}
 
@@ -39,25 +47,40 @@ func (p Doc) MustGet(key string) (value string) {
 // Set updates the value of the line of the key.
 //
 // Create a new line if the line of the key is not exist.
+// The duplicate lines of the key are removed, so the key has a single value afterwards.
+// If the key is overridden by an included file or a profile, the line of the document itself is
+// still updated in place, a new line is appended only if there is none, and the included lines are removed.
 func (p *Doc) Set(key, value string) {
-	if e, ok := p.props[key]; ok {
-		e.Value.(*line).value = value
-	} else {
-		p.props[key] = p.lines.PushBack(&line{typo: '=', key: key, value: value})
+	p.beforeWrite()
+
+	var owned *list.Element
+
+	for _, e := range p.elements(key) {
+		//  包含的行不会保存,只删除行本身,其前面的注释可能属于主文件
+		if e.Value.(*line).included {
+			p.lines.Remove(e)
+			continue
+		}
+
+		if owned != nil {
+			p.removeElem(owned)
+		}
+
+		owned = e
 	}
+
+	if owned == nil {
+		owned = p.lines.PushBack(&line{typo: '=', key: key})
+	}
+
+	l := owned.Value.(*line)
+	l.value, l.raw = value, ""
+	p.props[key] = owned
 }
 
-// Del deletes the exist line.
+// Del deletes the exist line, including the duplicate lines of the key.
 //
 // If the line is not exist, return false.
 func (p *Doc) Del(key string) bool {
-	if e, ok := p.props[key]; ok {
-		p.Uncomment(key)
-		p.lines.Remove(e)
-		delete(p.props, key)
-
-		return true
-	}
-
-	return false
+	return p.DelAll(key)
 }
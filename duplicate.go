@@ -0,0 +1,126 @@
+package properties
+
+import "container/list"
+
+// DuplicatePolicy defines how Load handles the keys appearing more than once.
+type DuplicatePolicy int
+
+const (
+	// DuplicateKeepAll keeps all of the occurrences in the document,
+	// Get returns the last one and GetAll returns all of them.
+	DuplicateKeepAll DuplicatePolicy = iota
+	// DuplicateLastWins keeps only the last occurrence, the former ones are dropped.
+	DuplicateLastWins
+	// DuplicateFirstWins keeps only the first occurrence, the latter ones are dropped.
+	DuplicateFirstWins
+	// DuplicateError reports the duplicate key as a ParseError.
+	DuplicateError
+)
+
+// GetAll retrieves the values of all of the occurrences of the key in document order.
+//
+// If the line is not exist, nil will be returned.
+func (p Doc) GetAll(key string) []string {
+	var values []string
+
+	for _, e := range p.elements(key) {
+		values = append(values, e.Value.(*line).value)
+	}
+
+	return values
+}
+
+// SetAll updates the values of all of the occurrences of the key in document order.
+//
+// The redundant occurrences are deleted and the extra values are appended after the last occurrence.
+// If values is empty, the key is deleted.
+func (p *Doc) SetAll(key string, values ...string) {
+	p.beforeWrite()
+
+	if len(values) == 0 {
+		p.DelAll(key)
+		return
+	}
+
+	elems := p.elements(key)
+
+	for i, e := range elems {
+		if i >= len(values) {
+			p.removeElem(e)
+			continue
+		}
+
+		l := e.Value.(*line)
+		l.value, l.raw = values[i], ""
+	}
+
+	if len(elems) > len(values) {
+		elems = elems[:len(values)]
+	}
+
+	for _, v := range values[len(elems):] {
+		l := &line{typo: '=', key: key, value: v}
+
+		if n := len(elems); n > 0 {
+			elems = append(elems, p.lines.InsertAfter(l, elems[n-1]))
+		} else {
+			elems = append(elems, p.lines.PushBack(l))
+		}
+	}
+
+	p.props[key] = elems[len(elems)-1]
+}
+
+// DelAll deletes all of the occurrences of the key, including their comments.
+//
+// If the line is not exist, return false.
+func (p *Doc) DelAll(key string) bool {
+	p.beforeWrite()
+
+	elems := p.elements(key)
+
+	for _, e := range elems {
+		p.removeElem(e)
+	}
+
+	return len(elems) > 0
+}
+
+// elements gives all of the property elements of the key in document order.
+func (p Doc) elements(key string) []*list.Element {
+	if _, ok := p.props[key]; !ok {
+		return nil
+	}
+
+	var elems []*list.Element
+
+	for e := p.lines.Front(); e != nil; e = e.Next() {
+		if l := e.Value.(*line); l.isProperty() && l.key == key {
+			elems = append(elems, e)
+		}
+	}
+
+	return elems
+}
+
+// removeElem removes the property element with its comments,
+// and repoints the key to its last remaining occurrence.
+func (p *Doc) removeElem(e *list.Element) {
+	key := e.Value.(*line).key
+
+	p.uncommentElem(e)
+	p.lines.Remove(e)
+
+	if p.props[key] != e {
+		return
+	}
+
+	delete(p.props, key)
+
+	for i := p.lines.Back(); i != nil; i = i.Prev() {
+		if l := i.Value.(*line); l.isProperty() && l.key == key {
+			p.props[key] = i
+			return
+		}
+	}
+}
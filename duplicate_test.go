@@ -0,0 +1,66 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const dupProps = "#c1\na=1\nb=2\n#c2\na=3\n"
+
+func TestDuplicateKeepAll(t *testing.T) {
+	that := assert.New(t)
+
+	doc, err := LoadString(dupProps)
+	that.Nil(err)
+	that.Equal("3", doc.Str("a"))
+	that.Equal([]string{"1", "3"}, doc.GetAll("a"))
+	that.Nil(doc.GetAll("NOT-EXIST"))
+
+	doc.Set("a", "4")
+	that.Equal("b=2\n#c2\na=4\n", doc.String())
+
+	doc.SetAll("b", "5", "6")
+	that.Equal("b=5\nb=6\n#c2\na=4\n", doc.String())
+	that.Equal("6", doc.Str("b"))
+
+	doc.SetAll("b", "7")
+	that.Equal("b=7\n#c2\na=4\n", doc.String())
+
+	doc.SetAll("c", "8", "9")
+	that.Equal([]string{"8", "9"}, doc.GetAll("c"))
+
+	that.True(doc.DelAll("c"))
+	that.False(doc.DelAll("c"))
+
+	doc.SetAll("b")
+	that.Equal("#c2\na=4\n", doc.String())
+}
+
+func TestDuplicateDel(t *testing.T) {
+	doc, _ := LoadString(dupProps)
+	assert.True(t, doc.Del("a"))
+	assert.Equal(t, "b=2\n", doc.String())
+
+	_, exist := doc.Get("a")
+	assert.False(t, exist)
+}
+
+func TestDuplicatePolicy(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadString(dupProps, WithDuplicates(DuplicateLastWins))
+	that.Equal("b=2\n#c2\na=3\n", doc.String())
+
+	doc, _ = LoadString(dupProps, WithDuplicates(DuplicateFirstWins))
+	that.Equal("#c1\na=1\nb=2\n", doc.String())
+	that.Equal("1", doc.Str("a"))
+
+	doc, err := LoadString(dupProps, WithDuplicates(DuplicateError))
+	that.Nil(doc)
+	that.Equal(&ParseError{Line: 5, Column: 1, Text: "a=3", Reason: "duplicate key"}, err)
+
+	doc, err = LoadString(dupProps, WithDuplicates(DuplicateError), WithParseMode(ParseTolerant))
+	that.Equal("3", doc.Str("a"))
+	that.Len(err, 1)
+}
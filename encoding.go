@@ -0,0 +1,188 @@
+package properties
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding defines the character encoding of the document bytes.
+type Encoding int
+
+const (
+	// EncodingUTF8 is the default encoding, the leading BOM is stripped.
+	EncodingUTF8 Encoding = iota
+	// EncodingISO88591 is the ISO-8859-1(Latin-1) encoding used by java tooling.
+	EncodingISO88591
+	// EncodingUTF16 is the UTF-16 encoding whose byte order is decided by the BOM,
+	// big endian if there is no BOM.
+	EncodingUTF16
+	// EncodingUTF16LE is the little endian UTF-16 encoding.
+	EncodingUTF16LE
+	// EncodingUTF16BE is the big endian UTF-16 encoding.
+	EncodingUTF16BE
+	// EncodingAuto detects the encoding by the BOM, then UTF-8 if the bytes are valid UTF-8,
+	// otherwise ISO-8859-1.
+	EncodingAuto
+)
+
+// nolint gochecknoglobals
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// String gives the name of the encoding.
+func (e Encoding) String() string {
+	switch e {
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingISO88591:
+		return "ISO-8859-1"
+	case EncodingUTF16:
+		return "UTF-16"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingAuto:
+		return "auto"
+	}
+
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// decode converts the bytes in the encoding to the UTF-8 text,
+// and returns the resolved encoding and whether there is a BOM.
+func decode(b []byte, enc Encoding) (text string, resolved Encoding, bom bool, err error) {
+	if enc == EncodingAuto {
+		switch {
+		case bytes.HasPrefix(b, bomUTF8):
+			enc = EncodingUTF8
+		case bytes.HasPrefix(b, bomUTF16LE), bytes.HasPrefix(b, bomUTF16BE):
+			enc = EncodingUTF16
+		case utf8.Valid(b):
+			enc = EncodingUTF8
+		default:
+			enc = EncodingISO88591
+		}
+	}
+
+	switch enc {
+	case EncodingUTF8:
+		bom = bytes.HasPrefix(b, bomUTF8)
+		if bom {
+			b = b[len(bomUTF8):]
+		}
+
+		return string(b), enc, bom, nil
+	case EncodingISO88591:
+		runes := make([]rune, len(b))
+		for i, c := range b {
+			runes[i] = rune(c)
+		}
+
+		return string(runes), enc, false, nil
+	case EncodingUTF16, EncodingUTF16LE, EncodingUTF16BE:
+		return decodeUTF16(b, enc)
+	}
+
+	return "", enc, false, fmt.Errorf("unsupported encoding %v", enc)
+}
+
+func decodeUTF16(b []byte, enc Encoding) (string, Encoding, bool, error) {
+	bom := false
+
+	switch {
+	case bytes.HasPrefix(b, bomUTF16LE) && enc != EncodingUTF16BE:
+		enc, bom, b = EncodingUTF16LE, true, b[2:]
+	case bytes.HasPrefix(b, bomUTF16BE) && enc != EncodingUTF16LE:
+		enc, bom, b = EncodingUTF16BE, true, b[2:]
+	case enc == EncodingUTF16:
+		enc = EncodingUTF16BE
+	}
+
+	if len(b)%2 != 0 {
+		return "", enc, bom, errors.New("odd length of UTF-16 bytes")
+	}
+
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		if enc == EncodingUTF16LE {
+			u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+		} else {
+			u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		}
+	}
+
+	return string(utf16.Decode(u)), enc, bom, nil
+}
+
+// encodedLen gives the length in bytes of the UTF-8 text encoded in the encoding.
+func encodedLen(text string, enc Encoding) int {
+	switch enc {
+	case EncodingISO88591:
+		return utf8.RuneCountInString(text)
+	case EncodingUTF16, EncodingUTF16LE, EncodingUTF16BE:
+		n := 0
+		for _, r := range text {
+			n += 2 * len(utf16.Encode([]rune{r}))
+		}
+
+		return n
+	}
+
+	return len(text)
+}
+
+// encode converts the UTF-8 text to the bytes in the encoding.
+// The characters out of ISO-8859-1 are escaped as \uXXXX if escape is true,
+// otherwise an error is returned.
+func encode(text string, enc Encoding, bom, escape bool) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch enc {
+	case EncodingUTF8:
+		if bom {
+			buf.Write(bomUTF8)
+		}
+
+		buf.WriteString(text)
+	case EncodingISO88591:
+		for _, r := range text {
+			switch {
+			case r <= 0xFF:
+				buf.WriteByte(byte(r))
+			case escape:
+				for _, u := range utf16.Encode([]rune{r}) {
+					buf.WriteString(`\u` + hex4(rune(u)))
+				}
+			default:
+				return nil, fmt.Errorf("character %q can not be encoded in ISO-8859-1", r)
+			}
+		}
+	case EncodingUTF16, EncodingUTF16LE, EncodingUTF16BE:
+		runes := []rune(text)
+		if bom || enc == EncodingUTF16 {
+			runes = append([]rune{0xFEFF}, runes...)
+		}
+
+		le := enc == EncodingUTF16LE
+		for _, u := range utf16.Encode(runes) {
+			if le {
+				buf.WriteByte(byte(u))
+				buf.WriteByte(byte(u >> 8))
+			} else {
+				buf.WriteByte(byte(u >> 8))
+				buf.WriteByte(byte(u))
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported output encoding %v", enc)
+	}
+
+	return buf.Bytes(), nil
+}
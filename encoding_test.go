@@ -0,0 +1,83 @@
+package properties
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodingBOM(t *testing.T) {
+	that := assert.New(t)
+	src := append([]byte{0xEF, 0xBB, 0xBF}, "key=值\n"...)
+
+	doc, err := LoadBytes(src)
+	that.Nil(err)
+	that.Equal(map[string]string{"key": "值"}, doc.Map())
+
+	var buf bytes.Buffer
+	that.Nil(doc.Save(&buf))
+	that.Equal(src, buf.Bytes())
+
+	buf.Reset()
+	that.Nil(doc.Save(&buf, WithBOM(false)))
+	that.Equal("key=值\n", buf.String())
+}
+
+func TestEncodingISO88591(t *testing.T) {
+	that := assert.New(t)
+	src := []byte("caf\xe9=cr\xe8me\n")
+
+	doc, err := LoadBytes(src, WithEncoding(EncodingISO88591))
+	that.Nil(err)
+	that.Equal("crème", doc.Str("café"))
+
+	var buf bytes.Buffer
+	that.Nil(doc.Save(&buf))
+	that.Equal(src, buf.Bytes())
+
+	doc.Set("café", "中")
+	that.NotNil(doc.Save(&buf))
+
+	doc, _ = LoadBytes(src, WithEncoding(EncodingISO88591), WithDialect(DialectJava))
+	doc.Set("café", "中")
+	buf.Reset()
+	that.Nil(doc.Save(&buf))
+	that.Equal("caf\xe9=\\u4E2D\n", buf.String())
+}
+
+func TestEncodingUTF16(t *testing.T) {
+	that := assert.New(t)
+	le := []byte{0xFF, 0xFE, 'a', 0, '=', 0, 0x2d, 0x4e, '\n', 0}
+
+	doc, err := LoadBytes(le, WithEncoding(EncodingUTF16))
+	that.Nil(err)
+	that.Equal("中", doc.Str("a"))
+
+	var buf bytes.Buffer
+	that.Nil(doc.Save(&buf))
+	that.Equal(le, buf.Bytes())
+
+	buf.Reset()
+	that.Nil(doc.Save(&buf, WithOutputEncoding(EncodingUTF16BE)))
+	that.Equal([]byte{0xFE, 0xFF, 0, 'a', 0, '=', 0x4e, 0x2d, 0, '\n'}, buf.Bytes())
+
+	_, err = LoadBytes(le[:3], WithEncoding(EncodingUTF16))
+	that.NotNil(err)
+}
+
+func TestEncodingAuto(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadBytes([]byte("a=\xe9"), WithEncoding(EncodingAuto))
+	that.Equal("é", doc.Str("a"))
+	that.Equal(EncodingISO88591, doc.encoding)
+
+	doc, _ = LoadBytes([]byte("a=é"), WithEncoding(EncodingAuto))
+	that.Equal("é", doc.Str("a"))
+	that.Equal(EncodingUTF8, doc.encoding)
+
+	doc, _ = LoadBytes([]byte{0xFE, 0xFF, 0, 'a'}, WithEncoding(EncodingAuto))
+	that.Equal(EncodingUTF16BE, doc.encoding)
+	that.Equal("UTF-16BE", doc.encoding.String())
+}
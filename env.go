@@ -0,0 +1,77 @@
+package properties
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvMapping defines how the environment variables are mapped to the properties.
+//
+// By default, the variable APP_DB_HOST with the Prefix APP_ is mapped to the key db.host,
+// the single '_' becomes '.', the double "__" becomes a literal '_', and the key is lower-cased.
+type EnvMapping struct {
+	// Prefix selects the variables, it's stripped from the key, like APP_.
+	Prefix string
+	// KeepCase keeps the case of the key instead of lower-casing it.
+	KeepCase bool
+	// Mapper customizes the mapping of the variable name(without the prefix) to the key,
+	// the variable is ignored if ok is false.
+	Mapper func(name string) (key string, ok bool)
+}
+
+// Key maps the environment variable name to the property key.
+// The ok is false if the variable is not selected by the mapping.
+func (m EnvMapping) Key(name string) (key string, ok bool) {
+	if !strings.HasPrefix(name, m.Prefix) || len(name) == len(m.Prefix) {
+		return "", false
+	}
+
+	name = name[len(m.Prefix):]
+
+	if m.Mapper != nil {
+		return m.Mapper(name)
+	}
+
+	parts := strings.Split(name, "__")
+	for i, part := range parts {
+		parts[i] = strings.Replace(part, "_", ".", -1)
+	}
+
+	key = strings.Join(parts, "_")
+	if !m.KeepCase {
+		key = strings.ToLower(key)
+	}
+
+	return key, true
+}
+
+// LoadEnv creates the properties document from the environment variables of the process.
+//
+// It can be used alone or as a higher-priority layer of Layered.
+func LoadEnv(m EnvMapping) (doc *Doc, err error) {
+	return LoadEnviron(os.Environ(), m)
+}
+
+// LoadEnviron creates the properties document from the environment variables in the form "NAME=value".
+// The properties are sorted by the variable names, and their Position.Source is like env:NAME.
+func LoadEnviron(environ []string, m EnvMapping) (doc *Doc, err error) {
+	doc = New()
+	environ = append([]string{}, environ...)
+	sort.Strings(environ)
+
+	for _, kv := range environ {
+		eq := strings.IndexByte(kv, '=')
+		if eq <= 0 {
+			continue
+		}
+
+		name, value := kv[:eq], kv[eq+1:]
+		if key, ok := m.Key(name); ok && key != "" {
+			doc.Set(key, value)
+			doc.props[key].Value.(*line).pos = Position{Source: "env:" + name}
+		}
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,56 @@
+package properties
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvMappingKey(t *testing.T) {
+	that := assert.New(t)
+	m := EnvMapping{Prefix: "APP_"}
+
+	key, ok := m.Key("APP_DB_HOST")
+	that.True(ok)
+	that.Equal("db.host", key)
+
+	key, _ = m.Key("APP_MAX__POOL_SIZE")
+	that.Equal("max_pool.size", key)
+
+	_, ok = m.Key("OTHER_DB_HOST")
+	that.False(ok)
+
+	_, ok = m.Key("APP_")
+	that.False(ok)
+
+	key, _ = EnvMapping{Prefix: "APP_", KeepCase: true}.Key("APP_DB_Host")
+	that.Equal("DB.Host", key)
+
+	key, _ = EnvMapping{Mapper: func(name string) (string, bool) { return strings.ToLower(name), true }}.Key("PATH")
+	that.Equal("path", key)
+}
+
+func TestLoadEnviron(t *testing.T) {
+	that := assert.New(t)
+
+	env, err := LoadEnviron([]string{"APP_DB_HOST=db", "APP_DB_PORT=3306", "HOME=/root", "APP_URL=a=b"},
+		EnvMapping{Prefix: "APP_"})
+	that.Nil(err)
+	that.Equal(map[string]string{"db.host": "db", "db.port": "3306", "url": "a=b"}, env.Map())
+
+	pos, _ := env.Position("db.host")
+	that.Equal("env:APP_DB_HOST", pos.String())
+
+	file, _ := LoadString("db.host=localhost\ndb.name=test\n")
+	l := NewLayered(Layer{Name: "file", Doc: file}, Layer{Name: "env", Doc: env})
+	that.Equal("db", l.Str("db.host"))
+	that.Equal("test", l.Str("db.name"))
+
+	os.Setenv("PROPERTIES_TEST_KEY", "v")
+	defer os.Unsetenv("PROPERTIES_TEST_KEY")
+
+	env, _ = LoadEnv(EnvMapping{Prefix: "PROPERTIES_TEST_"})
+	that.Equal(map[string]string{"key": "v"}, env.Map())
+}
@@ -0,0 +1,51 @@
+package properties
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseMode defines how Load reacts to malformed input.
+type ParseMode int
+
+const (
+	// ParseLenient accepts any input, the problems are silently ignored.
+	ParseLenient ParseMode = iota
+	// ParseStrict stops at the first problem and returns it as a *ParseError.
+	ParseStrict
+	// ParseTolerant collects all of the problems into a ParseErrors,
+	// and still returns the best-effort document.
+	ParseTolerant
+)
+
+// ParseError describes a problem found when parsing the document.
+type ParseError struct {
+	File   string // 源名称,从流中加载时为空
+	Line   int    // 行号,从1开始
+	Column int    // 列号(字节),从1开始
+	Text   string // 出错的行的原始文本
+	Reason string // 出错原因
+}
+
+// Error gives the message like file:line:column: reason: text.
+func (e *ParseError) Error() string {
+	file := e.File
+	if file == "" {
+		file = "<input>"
+	}
+
+	return fmt.Sprintf("%s:%d:%d: %s: %q", file, e.Line, e.Column, e.Reason, e.Text)
+}
+
+// ParseErrors is the multi-error collected in ParseTolerant mode.
+type ParseErrors []*ParseError
+
+// Error gives the messages of all problems, one per line.
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
@@ -0,0 +1,59 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const badProps = "a=1\n=empty\nb=\xff\xfe\nc=\\u00zz\n"
+
+func TestLoadStrict(t *testing.T) {
+	that := assert.New(t)
+
+	doc, err := LoadString(badProps, WithParseMode(ParseStrict), WithSource("bad.properties"),
+		WithDialect(DialectJava))
+	that.Nil(doc)
+
+	pe, ok := err.(*ParseError)
+	that.True(ok)
+	that.Equal(&ParseError{File: "bad.properties", Line: 2, Column: 1, Text: "=empty", Reason: "empty key"}, pe)
+	that.Equal(`bad.properties:2:1: empty key: "=empty"`, pe.Error())
+
+	_, err = LoadString("a=1\n", WithParseMode(ParseStrict))
+	that.Nil(err)
+
+	//  默认方言
+	_, err = LoadString("a=1\n=empty\n", WithParseMode(ParseStrict))
+	that.Equal(&ParseError{Line: 2, Column: 1, Text: "=empty", Reason: "empty key"}, err)
+
+	_, err = LoadString("a=1\n  :x\n", WithParseMode(ParseStrict))
+	that.Equal(&ParseError{Line: 2, Column: 3, Text: "  :x", Reason: "empty key"}, err)
+
+	doc, err = LoadString("=empty\n")
+	that.Nil(err)
+	that.Equal("=empty\n", doc.String())
+}
+
+func TestLoadTolerant(t *testing.T) {
+	that := assert.New(t)
+
+	doc, err := LoadString(badProps, WithParseMode(ParseTolerant), WithDialect(DialectJava))
+	that.NotNil(doc)
+	that.Equal("1", doc.Str("a"))
+
+	errs, ok := err.(ParseErrors)
+	that.True(ok)
+	that.Len(errs, 3)
+	that.Equal(2, errs[0].Line)
+	that.Equal(ParseError{Line: 3, Column: 3, Text: "b=\xff\xfe", Reason: "invalid UTF-8"}, *errs[1])
+	that.Equal(ParseError{Line: 4, Column: 3, Text: `c=\u00zz`, Reason: `malformed \uXXXX encoding`}, *errs[2])
+	that.Contains(errs.Error(), "<input>:4:3")
+}
+
+func TestLoadLenient(t *testing.T) {
+	doc, err := LoadString(badProps, WithDialect(DialectJava))
+	assert.Nil(t, err)
+	assert.Equal(t, "empty", doc.Str(""))
+	assert.Equal(t, `\u00zz`, doc.Str("c"))
+}
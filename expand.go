@@ -0,0 +1,183 @@
+package properties
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GetRaw retrieves the value from Doc without expansion.
+//
+// If the line is not exist, the exist is false.
+func (p Doc) GetRaw(key string) (value string, exist bool) {
+	if e, ok := p.props[key]; ok {
+		return e.Value.(*line).value, ok
+	}
+
+	return "", false
+}
+
+// Lookup retrieves the expanded value from Doc.
+//
+// The references are expanded recursively:
+//
+//	${key}           the value of the other key
+//	${env:VAR}       the environment variable VAR
+//	${key:-default}  the default if the key is not exist or empty
+//	${key:?message}  an error with message if the key is not exist or empty
+//
+// The references can be nested like ${db.${env:STAGE}.host}.
+// An error is returned for the undefined keys and the cyclic references.
+//
+// If the line is not exist, the exist is false.
+func (p Doc) Lookup(key string) (value string, exist bool, err error) {
+	raw, ok := p.GetRaw(key)
+	if !ok || p.noExpansion {
+		return raw, ok, nil
+	}
+
+	value, err = expand(raw, key, p.GetRaw)
+
+	return value, true, err
+}
+
+// SetExpansion enables or disables the expansion of ${...} references in Get, default enabled.
+func (p *Doc) SetExpansion(enabled bool) {
+	p.noExpansion = !enabled
+}
+
+// expand expands the references in the raw value of the key.
+func expand(raw, key string, lookup func(string) (string, bool)) (string, error) {
+	x := &expander{lookup: lookup, chain: []string{key}}
+	return x.expand(raw)
+}
+
+// expander expands the ${...} references with the cycle detection.
+type expander struct {
+	lookup func(string) (string, bool)
+	chain  []string //  正在展开的key链
+}
+
+func (x *expander) expand(s string) (string, error) {
+	var b strings.Builder
+
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		end := matchBrace(s, start+2)
+		if end < 0 { //  没有闭合的引用按原样保留
+			b.WriteString(s)
+			return b.String(), nil
+		}
+
+		v, err := x.resolve(s[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(s[:start])
+		b.WriteString(v)
+		s = s[end+1:]
+	}
+}
+
+// matchBrace gives the index of the '}' closing the reference starting at from.
+func matchBrace(s string, from int) int {
+	depth := 1
+
+	for i := from; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// resolve resolves the expression inside ${...}.
+func (x *expander) resolve(expr string) (string, error) {
+	name, op, arg := splitExpr(expr)
+
+	name, err := x.expand(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok, err := x.value(name)
+	if err != nil {
+		return "", err
+	}
+
+	if ok && (value != "" || op == "") {
+		return value, nil
+	}
+
+	switch op {
+	case ":-":
+		return x.expand(arg)
+	case ":?":
+		msg, err := x.expand(arg)
+		if err != nil {
+			return "", err
+		}
+
+		return "", fmt.Errorf("%s: %s", name, msg)
+	}
+
+	return "", fmt.Errorf("undefined key %s referenced by %s", name, x.chain[len(x.chain)-1])
+}
+
+// value gives the expanded value of the key or the environment variable.
+func (x *expander) value(name string) (string, bool, error) {
+	if strings.HasPrefix(name, "env:") {
+		v, ok := os.LookupEnv(name[len("env:"):])
+		return v, ok, nil
+	}
+
+	raw, ok := x.lookup(name)
+	if !ok {
+		return "", false, nil
+	}
+
+	for i, k := range x.chain {
+		if k == name {
+			return "", false, fmt.Errorf("expansion cycle: %s", strings.Join(append(x.chain[i:], name), " -> "))
+		}
+	}
+
+	x.chain = append(x.chain, name)
+	v, err := x.expand(raw)
+	x.chain = x.chain[:len(x.chain)-1]
+
+	return v, true, err
+}
+
+// splitExpr splits the expression to the name, the operator(":-", ":?" or "") and the argument,
+// the operators inside the nested references are ignored.
+func splitExpr(expr string) (name, op, arg string) {
+	depth := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case strings.HasPrefix(expr[i:], "${"):
+			depth++
+			i++
+		case expr[i] == '}':
+			depth--
+		case depth == 0 && (strings.HasPrefix(expr[i:], ":-") || strings.HasPrefix(expr[i:], ":?")):
+			return expr[:i], expr[i : i+2], expr[i+2:]
+		}
+	}
+
+	return expr, "", ""
+}
@@ -0,0 +1,73 @@
+package properties
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const expandProps = `
+host=localhost
+port=8080
+url=http://${host}:${port}/${path:-api}
+stage=dev
+dev.db=dev-db
+db=${${stage}.db}
+home=${env:PROPERTIES_TEST_HOME}
+required=${missing:?missing is required}
+undefined=${missing}
+a=${b}
+b=${c}
+c=${a}
+open=${not closed
+`
+
+func TestExpand(t *testing.T) {
+	that := assert.New(t)
+
+	os.Setenv("PROPERTIES_TEST_HOME", "/home/bingoo")
+	defer os.Unsetenv("PROPERTIES_TEST_HOME")
+
+	doc, _ := LoadString(expandProps)
+
+	that.Equal("http://localhost:8080/api", doc.Str("url"))
+	that.Equal("dev-db", doc.Str("db"))
+	that.Equal("/home/bingoo", doc.Str("home"))
+	that.Equal("${not closed", doc.Str("open"))
+
+	raw, _ := doc.GetRaw("url")
+	that.Equal("http://${host}:${port}/${path:-api}", raw)
+
+	_, _, err := doc.Lookup("required")
+	that.EqualError(err, "missing: missing is required")
+
+	_, _, err = doc.Lookup("undefined")
+	that.EqualError(err, "undefined key missing referenced by undefined")
+	that.Equal("${missing}", doc.Str("undefined"))
+
+	_, _, err = doc.Lookup("a")
+	that.EqualError(err, "expansion cycle: a -> b -> c -> a")
+	that.Panics(func() { doc.MustGet("a") })
+
+	doc.SetExpansion(false)
+	that.Equal("${b}", doc.Str("a"))
+	that.Contains(doc.String(), "url=http://${host}:${port}/${path:-api}\n")
+}
+
+func TestExpandPopulate(t *testing.T) {
+	doc, _ := LoadString("port=80\naddr=:${port}\nbad=${bad}")
+
+	var v struct {
+		Addr string
+	}
+
+	assert.Nil(t, doc.Populate(&v, "prop"))
+	assert.Equal(t, ":80", v.Addr)
+
+	var b struct {
+		Bad string
+	}
+
+	assert.NotNil(t, doc.Populate(&b, "prop"))
+}
@@ -0,0 +1,135 @@
+package properties
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bingoohuang/gor"
+)
+
+// FlagBinding binds a flag.FlagSet to a Doc, see BindFlags.
+type FlagBinding struct {
+	fs   *flag.FlagSet
+	doc  *Doc
+	keys map[string]string //  flag名称 -> 属性key
+	sets *setFlag
+}
+
+// BindFlags binds the flag set to the doc, it should be called after the flags are registered
+// and before the flag set is parsed.
+//
+// Each registered flag gets its default from the property of the same name(in any case like Populate).
+// A repeatable flag -D key=value (and its alias --set key=value) is registered like java's system properties.
+// After parsing, the explicitly set flags and -D values are given by Overlay to override the doc.
+func BindFlags(fs *flag.FlagSet, doc *Doc) (*FlagBinding, error) {
+	b := &FlagBinding{fs: fs, doc: doc, keys: make(map[string]string), sets: &setFlag{}}
+
+	var err error
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+
+		key, value, ok := b.lookup(f.Name)
+		if !ok {
+			return
+		}
+
+		if err = f.Value.Set(value); err != nil {
+			err = fmt.Errorf("flag -%s: invalid default %q from property %s: %v", f.Name, value, key, err)
+			return
+		}
+
+		b.keys[f.Name] = key
+		f.DefValue = f.Value.String()
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"D", "set"} {
+		if fs.Lookup(name) == nil {
+			fs.Var(b.sets, name, "set the property as key=value, repeatable")
+		}
+	}
+
+	return b, nil
+}
+
+func (b *FlagBinding) lookup(name string) (key, value string, ok bool) {
+	v, ok := gor.TryAnyCase(name, func(k string) (interface{}, bool) {
+		if v, ok := b.doc.Get(k); ok {
+			key = k
+			return v, true
+		}
+
+		return nil, false
+	})
+	if !ok {
+		return "", "", false
+	}
+
+	return key, v.(string), true
+}
+
+// Overlay gives the document of the explicitly set flags and the -D/--set values,
+// the latter ones take precedence.
+func (b *FlagBinding) Overlay() *Doc {
+	doc := New()
+
+	b.fs.Visit(func(f *flag.Flag) {
+		if f.Value == b.sets {
+			return
+		}
+
+		key, ok := b.keys[f.Name]
+		if !ok {
+			key = f.Name
+		}
+
+		doc.Set(key, f.Value.String())
+	})
+
+	for _, kv := range b.sets.values {
+		doc.Set(kv[0], kv[1])
+	}
+
+	return doc
+}
+
+// Layered gives the bound doc overlaid by the command-line flags.
+func (b *FlagBinding) Layered() *Layered {
+	return NewLayered(Layer{Name: "file", Doc: b.doc}, Layer{Name: "flags", Doc: b.Overlay()})
+}
+
+// setFlag is the repeatable key=value flag.
+type setFlag struct {
+	values [][2]string
+}
+
+func (s *setFlag) String() string {
+	if s == nil {
+		return ""
+	}
+
+	kvs := make([]string, len(s.values))
+	for i, kv := range s.values {
+		kvs[i] = kv[0] + "=" + kv[1]
+	}
+
+	return strings.Join(kvs, ",")
+}
+
+func (s *setFlag) Set(value string) error {
+	eq := strings.IndexByte(value, '=')
+	if eq <= 0 {
+		return fmt.Errorf("invalid property %q, should be key=value", value)
+	}
+
+	s.values = append(s.values, [2]string{value[:eq], value[eq+1:]})
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// nolint gomnd
+package properties
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindFlags(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadString("port=8080\nhost=localhost\nlog-level=info\nname=app\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	port := fs.Int("port", 80, "port")
+	host := fs.String("host", "", "host")
+	level := fs.String("logLevel", "warn", "log level")
+	other := fs.Bool("other", false, "not in doc")
+
+	b, err := BindFlags(fs, doc)
+	that.Nil(err)
+	that.Equal(8080, *port)
+	that.Equal("8080", fs.Lookup("port").DefValue)
+	that.Equal("info", *level)
+	that.False(*other)
+
+	that.Nil(fs.Parse([]string{"-host", "example.com", "-D", "name=cli", "--set", "extra=1", "-logLevel", "debug"}))
+	that.Equal("example.com", *host)
+
+	that.Equal(map[string]string{"host": "example.com", "log-level": "debug", "name": "cli", "extra": "1"},
+		b.Overlay().Map())
+
+	l := b.Layered()
+	that.Equal(8080, l.Int("port"))
+	that.Equal("cli", l.Str("name"))
+	that.Equal("example.com", l.Str("host"))
+
+	fs.SetOutput(ioutil.Discard)
+	that.NotNil(fs.Parse([]string{"-D", "novalue"}))
+}
+
+func TestBindFlagsInvalidDefault(t *testing.T) {
+	doc, _ := LoadString("port=abc\n")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 80, "port")
+
+	_, err := BindFlags(fs, doc)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,151 @@
+package properties
+
+import (
+	"fmt"
+)
+
+// Operation is an edit recorded in the History.
+type Operation struct {
+	Name string //  Set, Del, Comment, Uncomment或Revert
+	Key  string //  编辑的key,Revert时为检查点的名称
+}
+
+// History records the edits of a document for undo and redo.
+//
+// Every state is a snapshot of the document, so undo and redo restore the exact lines,
+// include the comments removed by Del.
+type History struct {
+	doc         *Doc
+	size        int
+	undos       []record
+	redos       []record
+	checkpoints map[string]*Doc
+}
+
+// record is an operation with the document state to restore.
+type record struct {
+	op    Operation
+	state *Doc
+}
+
+// defaultHistorySize is used when the size of NewHistory is not positive.
+const defaultHistorySize = 100
+
+// NewHistory creates a History for the doc, which keeps the latest size operations for undo.
+// The doc should be edited by the History only.
+func NewHistory(doc *Doc, size int) *History {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+
+	return &History{doc: doc, size: size, checkpoints: make(map[string]*Doc)}
+}
+
+// Doc gives the document edited.
+func (h *History) Doc() *Doc {
+	return h.doc
+}
+
+// Set sets the value of the key, see Doc.Set.
+func (h *History) Set(key, value string) {
+	h.do(Operation{Name: "Set", Key: key}, func() bool { h.doc.Set(key, value); return true })
+}
+
+// Del deletes the key, see Doc.Del.
+func (h *History) Del(key string) bool {
+	return h.do(Operation{Name: "Del", Key: key}, func() bool { return h.doc.Del(key) })
+}
+
+// Comment appends comments for the special line, see Doc.Comment.
+func (h *History) Comment(key, comments string) bool {
+	return h.do(Operation{Name: "Comment", Key: key}, func() bool { return h.doc.Comment(key, comments) })
+}
+
+// Uncomment removes all of the comments for the special line, see Doc.Uncomment.
+func (h *History) Uncomment(key string) bool {
+	return h.do(Operation{Name: "Uncomment", Key: key}, func() bool { return h.doc.Uncomment(key) })
+}
+
+// Checkpoint saves the current state of the document with the name, the former one with the same name is replaced.
+func (h *History) Checkpoint(name string) {
+	h.checkpoints[name] = h.doc.Snapshot()
+}
+
+// Revert restores the document to the checkpoint, which can be undone too.
+func (h *History) Revert(name string) error {
+	state, ok := h.checkpoints[name]
+	if !ok {
+		return fmt.Errorf("checkpoint %s not found", name)
+	}
+
+	h.do(Operation{Name: "Revert", Key: name}, func() bool { h.restore(state); return true })
+
+	return nil
+}
+
+// Undo reverts the latest operation, returns false if there is nothing to undo.
+func (h *History) Undo() (Operation, bool) {
+	return h.move(&h.undos, &h.redos)
+}
+
+// Redo reapplies the latest undone operation, returns false if there is nothing to redo.
+func (h *History) Redo() (Operation, bool) {
+	return h.move(&h.redos, &h.undos)
+}
+
+// Undos gives the operations can be undone, the latest one is the last.
+func (h *History) Undos() []Operation {
+	return operations(h.undos)
+}
+
+// Redos gives the operations can be redone, the next one is the last.
+func (h *History) Redos() []Operation {
+	return operations(h.redos)
+}
+
+// do applies the edit f, and records the operation if f returns true.
+func (h *History) do(op Operation, f func() bool) bool {
+	before := h.doc.Snapshot()
+	if !f() {
+		return false
+	}
+
+	h.undos = append(h.undos, record{op: op, state: before})
+	if len(h.undos) > h.size {
+		h.undos = h.undos[len(h.undos)-h.size:]
+	}
+
+	h.redos = nil
+
+	return true
+}
+
+// move pops the record from the stack to restore, and pushes the current state to the other stack.
+func (h *History) move(from, to *[]record) (Operation, bool) {
+	n := len(*from)
+	if n == 0 {
+		return Operation{}, false
+	}
+
+	r := (*from)[n-1]
+	*from = (*from)[:n-1]
+	*to = append(*to, record{op: r.op, state: h.doc.Snapshot()})
+
+	h.restore(r.state)
+
+	return r.op, true
+}
+
+func (h *History) restore(state *Doc) {
+	*h.doc = *state.Snapshot()
+}
+
+func operations(records []record) []Operation {
+	ops := make([]Operation, len(records))
+
+	for i, r := range records {
+		ops[i] = r.op
+	}
+
+	return ops
+}
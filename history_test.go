@@ -0,0 +1,73 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# comment\n  a  =  1\nb=2\n"
+	doc, _ := LoadString(text)
+	h := NewHistory(doc, 0)
+
+	h.Set("a", "10")
+	that.True(h.Del("b"))
+	that.False(h.Del("b"))
+	that.True(h.Comment("a", "new"))
+	that.False(h.Uncomment("x"))
+	that.Equal("# comment\n#new\n  a  =  10\n", doc.String())
+	that.Equal([]Operation{{"Set", "a"}, {"Del", "b"}, {"Comment", "a"}}, h.Undos())
+
+	op, ok := h.Undo()
+	that.True(ok)
+	that.Equal(Operation{"Comment", "a"}, op)
+	that.Equal("# comment\n  a  =  10\n", doc.String())
+
+	h.Undo()
+	h.Undo()
+	that.Equal(text, doc.String())
+
+	_, ok = h.Undo()
+	that.False(ok)
+	that.Equal([]Operation{{"Comment", "a"}, {"Del", "b"}, {"Set", "a"}}, h.Redos())
+
+	op, ok = h.Redo()
+	that.True(ok)
+	that.Equal(Operation{"Set", "a"}, op)
+	that.Equal("# comment\n  a  =  10\nb=2\n", doc.String())
+
+	//  新的操作清空redo
+	h.Set("c", "3")
+	that.Empty(h.Redos())
+	_, ok = h.Redo()
+	that.False(ok)
+}
+
+func TestHistoryCheckpoint(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# comment a\na=1\n# comment b\nb=2\n"
+	doc, _ := LoadString(text)
+	h := NewHistory(doc, 2)
+
+	h.Checkpoint("start")
+	h.Del("a")
+	h.Uncomment("b")
+	h.Set("b", "3")
+	that.Equal("b=3\n", doc.String())
+	that.Len(h.Undos(), 2)
+
+	that.EqualError(h.Revert("missing"), "checkpoint missing not found")
+	that.Nil(h.Revert("start"))
+	that.Equal(text, doc.String())
+
+	pos, _ := doc.Position("b")
+	that.Equal(4, pos.Line)
+
+	op, _ := h.Undo()
+	that.Equal(Operation{"Revert", "start"}, op)
+	that.Equal("b=3\n", doc.String())
+}
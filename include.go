@@ -0,0 +1,139 @@
+package properties
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+const includeDirective = "@include"
+
+// parseInclude parses the "@include" or "@include?" directive line,
+// returns false if the line is not a directive.
+func (l *loader) parseInclude(s string) bool {
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+
+	rest := strings.TrimPrefix(trimmed, includeDirective)
+	if rest == trimmed {
+		return false
+	}
+
+	optional := strings.HasPrefix(rest, "?")
+	if optional {
+		rest = rest[1:]
+	}
+
+	//  类似@includes这样的不是包含指令
+	if rest != "" && !unicode.IsSpace(rune(rest[0])) {
+		return false
+	}
+
+	l.doc.lines.PushBack(&line{typo: '@', value: s, raw: s, eol: l.eol, pos: l.pos})
+	l.include(rest, optional, l.pos, s)
+
+	return true
+}
+
+// include loads the comma separated paths(or globs) relative to the including file,
+// and appends their lines as the included lines.
+func (l *loader) include(paths string, optional bool, pos Position, text string) {
+	for _, pattern := range strings.Split(paths, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(l.baseDir(), pattern)
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			l.failInclude(pos, text, "include "+pattern+": "+err.Error())
+			return
+		}
+
+		if len(matches) == 0 && !optional {
+			l.failInclude(pos, text, "include "+pattern+": no such file")
+			return
+		}
+
+		for _, file := range matches {
+			if l.includeFile(file, pos, text); l.stopped() {
+				return
+			}
+		}
+	}
+}
+
+func (l *loader) includeFile(file string, pos Position, text string) {
+	abs := absPath(file)
+
+	for i, f := range l.chain {
+		if f == abs {
+			l.failInclude(pos, text, "include cycle: "+strings.Join(append(l.chain[i:], abs), " -> "))
+			return
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		l.failInclude(pos, text, "include "+err.Error())
+		return
+	}
+
+	defer f.Close()
+
+	opts := *l.LoadOptions
+	opts.Source = file
+
+	child := &loader{LoadOptions: &opts, chain: append([]string{}, l.chain...)}
+	child.enter(file)
+
+	if err := child.load(f); err != nil {
+		l.failInclude(pos, text, "include "+file+": "+err.Error())
+		return
+	}
+
+	l.errs = append(l.errs, child.errs...)
+	if l.fatal = child.fatal; l.stopped() {
+		return
+	}
+
+	for e := child.doc.lines.Front(); e != nil; e = e.Next() {
+		elem := e.Value.(*line)
+		elem.included = true
+
+		if elem.isProperty() {
+			l.addProperty(elem, 1, elem.raw)
+		} else {
+			l.doc.lines.PushBack(elem)
+		}
+	}
+}
+
+func (l *loader) failInclude(pos Position, text, reason string) {
+	l.fail(&ParseError{File: pos.Source, Line: pos.Line, Column: 1, Text: text, Reason: reason})
+}
+
+// enter pushes the file to the including chain.
+func (l *loader) enter(file string) {
+	l.chain = append(l.chain, absPath(file))
+}
+
+// baseDir gives the directory that the include paths are relative to.
+func (l *loader) baseDir() string {
+	if l.Source == "" {
+		return "."
+	}
+
+	return filepath.Dir(l.Source)
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+
+	return file
+}
@@ -0,0 +1,114 @@
+package properties
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "include")
+	assert.Nil(t, err)
+
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(file), 0755))
+		assert.Nil(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+
+	return dir
+}
+
+func TestInclude(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.properties":       "a=1\n@include common.properties\n@include? missing.properties\ninclude = conf/*.properties\nb=app\n",
+		"common.properties":    "b=common\nc=common\n",
+		"conf/x.properties":    "x=1\n",
+		"conf/y.properties":    "y=1\n",
+		"conf/z.properties.bk": "z=1\n",
+	})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	app := filepath.Join(dir, "app.properties")
+
+	doc, err := LoadFile(app, WithIncludes(true))
+	that.Nil(err)
+	that.Equal(map[string]string{"a": "1", "b": "app", "c": "common", "x": "1", "y": "1", "include": "conf/*.properties"},
+		doc.Map())
+
+	pos, _ := doc.Position("c")
+	that.Equal(filepath.Join(dir, "common.properties"), pos.Source)
+	pos, _ = doc.Position("y")
+	that.Equal(filepath.Join(dir, "conf/y.properties"), pos.Source)
+
+	doc.Set("c", "app")
+	that.Equal("a=1\n@include common.properties\n@include? missing.properties\ninclude = conf/*.properties\nb=app\nc=app\n",
+		doc.String())
+
+	doc, _ = LoadFile(app)
+	that.Equal("", doc.Str("c"))
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.properties":       "@include b.properties\n",
+		"b.properties":       "@include a.properties\n",
+		"missing.properties": "k=v\n@include none.properties\n",
+	})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+
+	_, err := LoadFile(filepath.Join(dir, "a.properties"), WithIncludes(true))
+	pe, ok := err.(*ParseError)
+	that.True(ok)
+	that.Equal(filepath.Join(dir, "b.properties"), pe.File)
+	that.True(strings.HasPrefix(pe.Reason, "include cycle: "+filepath.Join(dir, "a.properties")+" -> "))
+
+	doc, err := LoadFile(filepath.Join(dir, "missing.properties"), WithIncludes(true), WithParseMode(ParseTolerant))
+	that.Equal("v", doc.Str("k"))
+	that.Len(err, 1)
+	that.Contains(err.Error(), "no such file")
+}
+
+func TestIncludeDuplicates(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"common.properties": "a=common\n",
+		"first.properties":  "@include common.properties\n# mine\na=override\n",
+		"last.properties":   "# mine\na=mine\n@include common.properties\n",
+	})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+
+	for _, c := range []struct {
+		policy      DuplicatePolicy
+		first, last string
+	}{
+		{DuplicateKeepAll, "override", "common"},
+		{DuplicateFirstWins, "common", "mine"},
+		{DuplicateLastWins, "override", "common"},
+	} {
+		for file, expected := range map[string]string{"first.properties": c.first, "last.properties": c.last} {
+			text, _ := ioutil.ReadFile(filepath.Join(dir, file))
+
+			doc, err := LoadFile(filepath.Join(dir, file), WithIncludes(true), WithDuplicates(c.policy))
+			that.Nil(err)
+			that.Equal(expected, doc.Str("a"), "policy %d, file %s", c.policy, file)
+			that.Equal(expected, doc.Map()["a"], "policy %d, file %s", c.policy, file)
+			that.Equal(string(text), doc.String(), "policy %d, file %s", c.policy, file)
+		}
+	}
+
+	doc, _ := LoadFile(filepath.Join(dir, "last.properties"), WithIncludes(true))
+	doc.Set("a", "new")
+	that.Equal("new", doc.Str("a"))
+	that.Equal(map[string]string{"a": "new"}, doc.Map())
+	that.Equal([]string{"new"}, doc.GetAll("a"))
+	that.Equal("# mine\na=new\n@include common.properties\n", doc.String())
+}
@@ -15,6 +15,7 @@ func (p Doc) Map() map[string]string {
 // If typo is '#' or '!' means current line is a comment.
 // If typo is ' ' means current line is a empty or a space line.
 // If typo is '=' or ':' means current line is a key-value pair.
+// If typo is '@' means current line is an include directive, see WithIncludes.
 // The traverse will be terminated if f return false.
 func (p Doc) Accept(f func(typo byte, value, key string) bool) {
 	for e := p.lines.Front(); e != nil; e = e.Next() {
@@ -26,11 +27,14 @@ func (p Doc) Accept(f func(typo byte, value, key string) bool) {
 }
 
 // Foreach traverses all of the key-value pairs in the document.
+//
+// Each key is traversed once with its effective value, the one Get retrieves,
+// at the position of the effective line, see GetAll for the duplicate values.
 // The traverse will be terminated if f return false.
 func (p Doc) Foreach(f func(value, key string) bool) {
 	for e := p.lines.Front(); e != nil; e = e.Next() {
 		elem := e.Value.(*line)
-		if !elem.isProperty() {
+		if !elem.isProperty() || p.props[elem.key] != e {
 			continue
 		}
 
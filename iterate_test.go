@@ -49,3 +49,18 @@ func Test_Foreach(t *testing.T) {
 
 	expect(t, "Foreach提前中断", count == 2)
 }
+
+func Test_ForeachDuplicates(t *testing.T) {
+	var keys, values []string
+
+	doc, _ := LoadString("a=1\nb=2\na=3\n")
+	doc.Foreach(func(value string, key string) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+
+		return true
+	})
+
+	assert.Equal(t, []string{"b", "a"}, keys)
+	assert.Equal(t, []string{"2", "3"}, values)
+}
@@ -0,0 +1,178 @@
+package properties
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Dialect defines the grammar used to parse and render the properties document.
+type Dialect int
+
+const (
+	// DialectSimple is the default single-line grammar described in the README.
+	DialectSimple Dialect = iota
+	// DialectJava follows the grammar of java.util.Properties.load,
+	// including continuation lines, escapes, \uXXXX sequences and whitespace separators.
+	DialectJava
+)
+
+// isJavaSpace tells whether c is a whitespace in the java properties grammar.
+func isJavaSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+// trimJavaSpace removes the leading whitespaces of a natural line.
+func trimJavaSpace(s string) string {
+	return strings.TrimLeft(s, " \t\f")
+}
+
+// needsContinuation tells whether the natural line ends with an odd number of backslashes.
+func needsContinuation(s string) bool {
+	n := 0
+	for i := len(s) - 1; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+
+	return n%2 == 1
+}
+
+// splitJava splits a logical line into the escaped key and value,
+// and returns the separator character('=', ':' or 0 for whitespace).
+func splitJava(s string) (key, value string, sep byte) {
+	keyLen, valueStart := 0, len(s)
+	hasSep, backslash := false, false
+
+	for keyLen < len(s) {
+		c := s[keyLen]
+		if (c == '=' || c == ':') && !backslash {
+			valueStart, hasSep, sep = keyLen+1, true, c
+			break
+		}
+
+		if isJavaSpace(c) && !backslash {
+			valueStart = keyLen + 1
+			break
+		}
+
+		backslash = c == '\\' && !backslash
+		keyLen++
+	}
+
+	for valueStart < len(s) {
+		c := s[valueStart]
+		if !isJavaSpace(c) {
+			if hasSep || (c != '=' && c != ':') {
+				break
+			}
+
+			hasSep, sep = true, c
+		}
+
+		valueStart++
+	}
+
+	return s[:keyLen], s[valueStart:], sep
+}
+
+// unescapeJava converts the escaped text of a key or a value to its real value.
+// Malformed \uXXXX sequences are kept as they are.
+func unescapeJava(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			if c != '\\' {
+				b.WriteByte(c)
+			}
+
+			continue
+		}
+
+		i++
+
+		switch c = s[i]; c {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			r, ok := parseUnicodeEscape(s[i+1:])
+			if !ok {
+				b.WriteString(`\u`)
+				continue
+			}
+
+			b.WriteRune(r)
+			i += 4
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// parseUnicodeEscape parses the XXXX part of a \uXXXX sequence.
+func parseUnicodeEscape(s string) (rune, bool) {
+	if len(s) < 4 {
+		return 0, false
+	}
+
+	v, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+
+	return rune(v), true
+}
+
+// escapeJava escapes a key or a value like java.util.Properties.store does,
+// except that the non-ASCII characters are kept as they are.
+func escapeJava(s string, isKey bool) string {
+	var b strings.Builder
+
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if i == 0 || isKey {
+				b.WriteByte('\\')
+			}
+
+			b.WriteByte(' ')
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r == 0x7f {
+				b.WriteString(`\u`)
+				b.WriteString(hex4(r))
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func hex4(r rune) string {
+	h := strings.ToUpper(strconv.FormatInt(int64(r), 16))
+	return strings.Repeat("0", 4-len(h)) + h
+}
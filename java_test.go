@@ -0,0 +1,58 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const javaProps = `# java style
+  fruits    apple, banana, \
+            pear, \
+            cantaloupe
+key\ with\ spaces = value
+tab\tkey:\tvalue
+path=c:\\windows
+unicode=\u4e2d\u6587
+empty
+colon\:key = a\=b
+trailing = x  
+`
+
+func TestLoadJava(t *testing.T) {
+	doc, err := LoadString(javaProps, WithDialect(DialectJava))
+
+	that := assert.New(t)
+	that.Nil(err)
+	that.Equal(map[string]string{
+		"fruits":          "apple, banana, pear, cantaloupe",
+		"key with spaces": "value",
+		"tab\tkey":        "\tvalue",
+		"path":            `c:\windows`,
+		"unicode":         "中文",
+		"empty":           "",
+		"colon:key":       "a=b",
+		"trailing":        "x  ",
+	}, doc.Map())
+
+	s, err := doc.Export()
+	that.Nil(err)
+	that.Equal(javaProps, s)
+}
+
+func TestSaveJava(t *testing.T) {
+	doc, _ := LoadString("a = 1\n", WithDialect(DialectJava))
+	doc.Set("a", " x=y\n")
+	doc.Set("b c", "中文#")
+
+	assert.Equal(t, "a = \\ x\\=y\\n\nb\\ c=中文\\#\n", doc.String())
+
+	reload, _ := LoadString(doc.String(), WithDialect(DialectJava))
+	assert.Equal(t, doc.Map(), reload.Map())
+}
+
+func TestUnescapeJava(t *testing.T) {
+	assert.Equal(t, `\uZZ`, unescapeJava(`\uZZ`))
+	assert.Equal(t, "a", unescapeJava(`a\`))
+	assert.Equal(t, "A", unescapeJava(`\u0041`))
+}
@@ -0,0 +1,39 @@
+package properties
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonPatchOp is an operation of RFC 6902 JSON Patch.
+type jsonPatchOp struct {
+	Op    string  `json:"op"`
+	Path  string  `json:"path"`
+	Value *string `json:"value,omitempty"`
+}
+
+// JSONPatch renders the events of DiffWith as an RFC 6902 JSON Patch,
+// which transforms the JSON object of the left properties to the right ones.
+//
+// Each key is a member of the object, like {"db.host": "localhost"} with the path "/db.host".
+// The Same, Commented and Moved events are ignored since they do not change the object.
+func JSONPatch(events []DiffEvent) ([]byte, error) {
+	ops := make([]jsonPatchOp, 0, len(events))
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+
+	for _, e := range events {
+		path := "/" + escaper.Replace(e.Key)
+		value := e.RightValue
+
+		switch e.ChangeType {
+		case Added:
+			ops = append(ops, jsonPatchOp{Op: "add", Path: path, Value: &value})
+		case Modified:
+			ops = append(ops, jsonPatchOp{Op: "replace", Path: path, Value: &value})
+		case Removed:
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: path})
+		}
+	}
+
+	return json.Marshal(ops)
+}
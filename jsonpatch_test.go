@@ -0,0 +1,22 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONPatch(t *testing.T) {
+	that := assert.New(t)
+
+	events := diffEvents("a=1\nb/c=2\nd~=4\n", "a=10\nd~=4\ne=\n", DiffOptions{Moves: true})
+
+	b, err := JSONPatch(events)
+	that.Nil(err)
+	that.Equal(`[{"op":"replace","path":"/a","value":"10"},`+
+		`{"op":"remove","path":"/b~1c"},`+
+		`{"op":"add","path":"/e","value":""}]`, string(b))
+
+	b, _ = JSONPatch(nil)
+	that.Equal("[]", string(b))
+}
@@ -0,0 +1,226 @@
+package properties
+
+// Layer is a named document in the Layered.
+type Layer struct {
+	Name string
+	Doc  *Doc
+}
+
+// Layered stacks several documents like defaults, file, environment and command-line,
+// the values are resolved through the stack by precedence,
+// the latter layer takes precedence over the former ones.
+type Layered struct {
+	layers []Layer
+}
+
+// LayerValue is the value of a key in a layer, see Explain.
+type LayerValue struct {
+	Layer    string   //  层的名称
+	Value    string   //  该层中的原始值
+	Position Position //  该层中定义的位置
+	Winner   bool     //  是否是最终生效的值
+}
+
+// NewLayered creates a Layered from the layers in the order of increasing precedence.
+func NewLayered(layers ...Layer) *Layered {
+	return &Layered{layers: append([]Layer{}, layers...)}
+}
+
+// Push adds a layer with the highest precedence.
+func (l *Layered) Push(name string, doc *Doc) *Layered {
+	l.layers = append(l.layers, Layer{Name: name, Doc: doc})
+	return l
+}
+
+// Layers gives the layers in the order of increasing precedence.
+func (l *Layered) Layers() []Layer {
+	return append([]Layer{}, l.layers...)
+}
+
+// GetRaw retrieves the value without expansion from the layer with the highest precedence.
+//
+// If the line is not exist in any layer, the exist is false.
+func (l *Layered) GetRaw(key string) (value string, exist bool) {
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if value, exist = l.layers[i].Doc.GetRaw(key); exist {
+			return value, exist
+		}
+	}
+
+	return "", false
+}
+
+// Lookup retrieves the expanded value like Doc.Lookup,
+// but the references are resolved through the layers too.
+func (l *Layered) Lookup(key string) (value string, exist bool, err error) {
+	raw, ok := l.GetRaw(key)
+	if !ok {
+		return "", false, nil
+	}
+
+	value, err = expand(raw, key, l.GetRaw)
+
+	return value, true, err
+}
+
+// Get retrieves the expanded value through the layers.
+//
+// If the line is not exist in any layer, the exist is false.
+// If the value can not be expanded, the raw value will be returned.
+func (l *Layered) Get(key string) (value string, exist bool) {
+	value, exist, err := l.Lookup(key)
+	if err != nil {
+		return l.GetRaw(key)
+	}
+
+	return value, exist
+}
+
+// MustGet returns the expanded value for the given key if exists or
+// panics otherwise.
+func (l *Layered) MustGet(key string) (value string) {
+	val, ok, err := l.Lookup(key)
+	if err != nil {
+		panic(err)
+	}
+
+	if ok {
+		return val
+	}
+
+	panic(key + " not found")
+}
+
+// Explain gives the values of the key in every layer containing it,
+// in the order of increasing precedence, and marks the winner.
+func (l *Layered) Explain(key string) []LayerValue {
+	var values []LayerValue
+
+	for _, layer := range l.layers {
+		if value, ok := layer.Doc.GetRaw(key); ok {
+			pos, _ := layer.Doc.Position(key)
+			values = append(values, LayerValue{Layer: layer.Name, Value: value, Position: pos})
+		}
+	}
+
+	if n := len(values); n > 0 {
+		values[n-1].Winner = true
+	}
+
+	return values
+}
+
+// Foreach traverses all of the keys with their winning raw values.
+// The keys are in the order of their first appearance from the lowest layer.
+// The traverse will be terminated if f return false.
+func (l *Layered) Foreach(f func(value, key string) bool) {
+	visited := make(map[string]bool)
+
+	for _, layer := range l.layers {
+		continues := true
+
+		layer.Doc.Foreach(func(_, key string) bool {
+			if visited[key] {
+				return true
+			}
+
+			visited[key] = true
+			value, _ := l.GetRaw(key)
+			continues = f(value, key)
+
+			return continues
+		})
+
+		if !continues {
+			return
+		}
+	}
+}
+
+// Map gets the map of the winning raw values.
+func (l *Layered) Map() map[string]string {
+	m := make(map[string]string)
+
+	l.Foreach(func(v, k string) bool { m[k] = v; return true })
+
+	return m
+}
+
+// Populate populates the expanded properties to the structure's field.
+func (l *Layered) Populate(b interface{}, tag string) error {
+	return populate(l, b, tag)
+}
+
+// StrOr retrieves the string value by key through the layers.
+// If the line is not exist, the def will be returned.
+func (l *Layered) StrOr(key, def string) string {
+	return strOr(l, key, def)
+}
+
+// IntOr retrieves the int value by key through the layers.
+// If the line is not exist, the def will be returned.
+func (l *Layered) IntOr(key string, def int) int {
+	return intOr(l, key, def)
+}
+
+// Int64Or retrieves the int64 value by key through the layers.
+// If the line is not exist, the def will be returned.
+func (l *Layered) Int64Or(key string, def int64) int64 {
+	return int64Or(l, key, def)
+}
+
+// Uint64Or Same as Int64Or, but the return type is uint64.
+func (l *Layered) Uint64Or(key string, def uint64) uint64 {
+	return uint64Or(l, key, def)
+}
+
+// Float64Or retrieve the float64 value by key through the layers.
+// If the line is not exist, the def will be returned.
+func (l *Layered) Float64Or(key string, def float64) float64 {
+	return float64Or(l, key, def)
+}
+
+// BoolOr retrieve the bool value by key through the layers, see Doc.BoolOr.
+func (l *Layered) BoolOr(key string, def bool) bool {
+	return boolOr(l, key, def)
+}
+
+// ObjectOr maps the value of the key to any object, see Doc.ObjectOr.
+func (l *Layered) ObjectOr(key string, def interface{}, f func(k, v string) (interface{}, error)) interface{} {
+	return objectOr(l, key, def, f)
+}
+
+// Str same as StrOr but the def is "".
+func (l *Layered) Str(key string) string {
+	return l.StrOr(key, "")
+}
+
+// Int is same as IntOr but the def is 0 .
+func (l *Layered) Int(key string) int {
+	return l.IntOr(key, 0)
+}
+
+// Int64 is same as Int64Or but the def is 0 .
+func (l *Layered) Int64(key string) int64 {
+	return l.Int64Or(key, 0)
+}
+
+// Uint64 same as Uint64Or but the def is 0 .
+func (l *Layered) Uint64(key string) uint64 {
+	return l.Uint64Or(key, 0)
+}
+
+// Float64 same as Float64Or but the def is 0.0 .
+func (l *Layered) Float64(key string) float64 {
+	return l.Float64Or(key, 0.0)
+}
+
+// Bool same as BoolOr but the def is false.
+func (l *Layered) Bool(key string) bool {
+	return l.BoolOr(key, false)
+}
+
+// Object is same as ObjectOr but the def is nil.
+func (l *Layered) Object(key string, f func(k, v string) (interface{}, error)) interface{} {
+	return l.ObjectOr(key, nil, f)
+}
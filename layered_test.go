@@ -0,0 +1,56 @@
+// nolint gomnd
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayered(t *testing.T) {
+	that := assert.New(t)
+
+	defaults, _ := LoadMap(map[string]string{"port": "80", "host": "localhost", "debug": "false"})
+	file, _ := LoadString("port=8080\nurl=http://${host}:${port}\n", WithSource("app.properties"))
+	cli, _ := LoadMap(map[string]string{"debug": "true"})
+
+	l := NewLayered(Layer{Name: "defaults", Doc: defaults}).Push("file", file).Push("cli", cli)
+	that.Len(l.Layers(), 3)
+
+	that.Equal(8080, l.Int("port"))
+	that.True(l.Bool("debug"))
+	that.Equal("http://localhost:8080", l.Str("url"))
+	that.Equal("http://localhost:8080", l.MustGet("url"))
+	that.Equal("x", l.StrOr("NOT-EXIST", "x"))
+	that.Panics(func() { l.MustGet("NOT-EXIST") })
+
+	that.Equal(map[string]string{
+		"port": "8080", "host": "localhost", "debug": "true", "url": "http://${host}:${port}",
+	}, l.Map())
+
+	that.Equal([]LayerValue{
+		{Layer: "defaults", Value: "80"},
+		{Layer: "file", Value: "8080", Position: Position{Source: "app.properties", Line: 1}, Winner: true},
+	}, l.Explain("port"))
+	that.Nil(l.Explain("NOT-EXIST"))
+
+	var c struct {
+		Port  int
+		Debug bool
+		URL   string `prop:"url"`
+	}
+
+	that.Nil(l.Populate(&c, "prop"))
+	that.Equal(8080, c.Port)
+	that.True(c.Debug)
+	that.Equal("http://localhost:8080", c.URL)
+
+	count := 0
+
+	l.Foreach(func(value, key string) bool {
+		count++
+		return count < 2
+	})
+
+	that.Equal(2, count)
+}
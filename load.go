@@ -1,16 +1,81 @@
 package properties
 
 import (
-	"bufio"
 	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// LoadOptions defines the options used to load the properties document.
+type LoadOptions struct {
+	// Dialect specifies the grammar of the document, default DialectSimple.
+	Dialect Dialect
+	// Source is the name used in the ParseError, LoadFile sets it to the file name.
+	Source string
+	// Mode specifies how the malformed input is reported, default ParseLenient.
+	Mode ParseMode
+	// Encoding specifies the encoding of the input bytes, default EncodingUTF8.
+	Encoding Encoding
+	// Duplicates specifies how the duplicate keys are handled, default DuplicateKeepAll.
+	Duplicates DuplicatePolicy
+	// Includes enables the include directives, see WithIncludes.
+	Includes bool
+}
+
+// LoadOption defines the option function for loading.
+type LoadOption func(*LoadOptions)
+
+// WithDialect specifies the grammar used to parse the document.
+func WithDialect(dialect Dialect) LoadOption {
+	return func(o *LoadOptions) { o.Dialect = dialect }
+}
+
+// WithSource specifies the source name of the document used in the ParseError.
+func WithSource(source string) LoadOption {
+	return func(o *LoadOptions) { o.Source = source }
+}
+
+// WithParseMode specifies how the malformed input is reported.
+func WithParseMode(mode ParseMode) LoadOption {
+	return func(o *LoadOptions) { o.Mode = mode }
+}
+
+// WithDuplicates specifies how the duplicate keys are handled.
+func WithDuplicates(policy DuplicatePolicy) LoadOption {
+	return func(o *LoadOptions) { o.Duplicates = policy }
+}
+
+// WithEncoding specifies the encoding of the input bytes.
+func WithEncoding(encoding Encoding) LoadOption {
+	return func(o *LoadOptions) { o.Encoding = encoding }
+}
+
+// WithIncludes enables the include directives like "@include common.properties",
+// "@include? optional.properties" or the property "include = a.properties, b.properties".
+//
+// The paths are relative to the including file(the directory of Source), and may be globs.
+// The included lines are kept in the document with their own Position.Source,
+// but they are not written by Save.
+func WithIncludes(enabled bool) LoadOption {
+	return func(o *LoadOptions) { o.Includes = enabled }
+}
+
+func makeLoadOptions(options []LoadOption) *LoadOptions {
+	o := &LoadOptions{}
+
+	for _, f := range options {
+		f(o)
+	}
+
+	return o
+}
+
 // LoadFile creates the properties document from a file or a stream.
-func LoadFile(file string) (doc *Doc, err error) {
+func LoadFile(file string, options ...LoadOption) (doc *Doc, err error) {
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
@@ -18,7 +83,7 @@ func LoadFile(file string) (doc *Doc, err error) {
 
 	defer f.Close()
 
-	return Load(f)
+	return Load(f, append([]LoadOption{WithSource(file)}, options...)...)
 }
 
 // LoadMap creates a new Properties struct from a string map.
@@ -34,66 +99,319 @@ func LoadMap(m map[string]string) (doc *Doc, err error) {
 }
 
 // LoadString creates the properties document from a string.
-func LoadString(s string) (doc *Doc, err error) {
-	return Load(strings.NewReader(s))
+func LoadString(s string, options ...LoadOption) (doc *Doc, err error) {
+	return Load(strings.NewReader(s), options...)
 }
 
 // LoadBytes creates the properties document from a string.
-func LoadBytes(s []byte) (doc *Doc, err error) {
-	return Load(bytes.NewReader(s))
+func LoadBytes(s []byte, options ...LoadOption) (doc *Doc, err error) {
+	return Load(bytes.NewReader(s), options...)
 }
 
 // Load creates the properties document from a file or a stream.
-func Load(reader io.Reader) (doc *Doc, err error) {
+//
+// In ParseStrict mode, the first problem is returned as a *ParseError.
+// In ParseTolerant mode, all problems are returned as ParseErrors along with the document.
+// The encoding and BOM of the input are remembered and used by Save.
+func Load(reader io.Reader, options ...LoadOption) (doc *Doc, err error) {
+	l := &loader{LoadOptions: makeLoadOptions(options)}
+	if l.Includes && l.Source != "" {
+		l.enter(l.Source)
+	}
+
+	if err := l.load(reader); err != nil {
+		return nil, err
+	}
+
+	if l.stopped() {
+		if l.fatal != nil {
+			return nil, l.fatal
+		}
+
+		return nil, l.errs[0]
+	}
+
+	if len(l.errs) > 0 {
+		return l.doc, l.errs
+	}
+
+	return l.doc, nil
+}
+
+// loader holds the state when loading a document.
+type loader struct {
+	*LoadOptions
+	doc    *Doc
+	text   string //  解码后的全部文本
+	offset int    //  下一行在text中的偏移
+	srcOff int    //  下一行在源字节中的偏移
+	srcEnc Encoding
+	cur    string //  当前行,不含行尾
+	eol    string //  当前行的行尾: "\n", "\r\n", "\r" 或者 ""(最后一行)
+	lineNo int
+	pos    Position //  当前行的位置
+	errs   ParseErrors
+	fatal  *ParseError
+	chain  []string //  正在加载的文件链,用于检测循环包含
+}
+
+// load reads and parses the document into l.doc, the problems are kept in errs and fatal.
+func (l *loader) load(reader io.Reader) error {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	text, encoding, bom, err := decode(b, l.Encoding)
+	if err != nil {
+		return err
+	}
+
+	l.text = text
+	l.srcEnc = encoding
+	l.srcOff = len(b) - encodedLen(text, encoding) //  BOM的长度
+
 	//  创建一个Properties对象
-	doc = New()
+	l.doc = New()
+	l.doc.dialect, l.doc.encoding, l.doc.bom = l.Dialect, encoding, bom
+
+	//  逐行读取
+	for l.scan() {
+		if l.lineNo == 1 && l.eol != "" {
+			l.doc.eol = l.eol
+		}
 
-	//  创建一个扫描器
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		//  逐行读取
-		l := scanner.Bytes()
+		switch {
+		case l.Includes && l.parseInclude(l.cur):
+		case l.Dialect == DialectJava:
+			l.parseJavaLine(l.cur)
+		default:
+			l.parseLine(l.cur)
+		}
 
-		//  遇到空行
-		if len(l) == 0 {
-			doc.lines.PushBack(&line{typo: ' ', value: string("")})
-			continue
+		if l.stopped() {
+			return nil
 		}
+	}
 
-		//  找到第一个非空白字符
-		pos := bytes.IndexFunc(l, func(r rune) bool { return !unicode.IsSpace(r) })
+	l.doc.noFinalEOL = l.lineNo > 0 && l.eol == ""
 
-		//  遇到空白行
-		if pos == -1 {
-			doc.lines.PushBack(&line{typo: ' ', value: string("")})
-			continue
+	return nil
+}
+
+// stopped tells whether the loading should be stopped by the problems found.
+func (l *loader) stopped() bool {
+	return l.fatal != nil || l.Mode == ParseStrict && len(l.errs) > 0
+}
+
+// scan reads the next natural line and checks its encoding.
+// The natural lines are terminated by "\n", "\r\n" or "\r".
+func (l *loader) scan() bool {
+	if l.offset >= len(l.text) {
+		return false
+	}
+
+	rest := l.text[l.offset:]
+	end := strings.IndexAny(rest, "\r\n")
+
+	switch {
+	case end < 0:
+		l.cur, l.eol = rest, ""
+	case strings.HasPrefix(rest[end:], "\r\n"):
+		l.cur, l.eol = rest[:end], "\r\n"
+	default:
+		l.cur, l.eol = rest[:end], rest[end:end+1]
+	}
+
+	l.lineNo++
+	l.pos = Position{Source: l.Source, Line: l.lineNo, Offset: l.srcOff}
+	l.offset += len(l.cur) + len(l.eol)
+	l.srcOff += encodedLen(l.cur, l.srcEnc) + len(l.eol)*encodedLen("\n", l.srcEnc)
+
+	if !utf8.ValidString(l.cur) {
+		l.report(l.lineNo, invalidUTF8Pos([]byte(l.cur)), l.cur, "invalid UTF-8")
+	}
+
+	return true
+}
+
+// report records a problem, it is ignored in ParseLenient mode.
+func (l *loader) report(lineNo, col int, text, reason string) {
+	if l.Mode == ParseLenient {
+		return
+	}
+
+	l.errs = append(l.errs, &ParseError{File: l.Source, Line: lineNo, Column: col, Text: text, Reason: reason})
+}
+
+// addProperty appends the property line according to the duplicate policy.
+func (l *loader) addProperty(elem *line, col int, text string) {
+	p := l.doc
+
+	firstWins := false
+
+	if e, ok := p.props[elem.key]; ok {
+		//  包含的行与主文件的行重复时只丢弃包含的行,主文件的行丢弃后保存时会丢失
+		mixed := e.Value.(*line).included != elem.included
+
+		switch l.Duplicates {
+		case DuplicateFirstWins:
+			if mixed && !elem.included {
+				firstWins = true
+				break
+			}
+
+			//  与DuplicateLastWins一致,注释随被丢弃的行一起删除
+			dropped := p.lines.PushBack(elem)
+			p.uncommentElem(dropped)
+			p.lines.Remove(dropped)
+
+			return
+		case DuplicateLastWins:
+			if !mixed || e.Value.(*line).included {
+				p.removeElem(e)
+			}
+		case DuplicateError:
+			l.fail(&ParseError{File: elem.pos.Source, Line: elem.pos.Line, Column: col, Text: text, Reason: "duplicate key"})
 		}
+	}
 
-		//  遇到注释行
-		if isComment(l[pos]) {
-			doc.lines.PushBack(&line{typo: l[pos], value: string(l)})
-			continue
+	if pushed := p.lines.PushBack(elem); !firstWins {
+		p.props[elem.key] = pushed
+	}
+
+	if l.Includes && !elem.included && elem.key == "include" {
+		l.include(elem.value, false, elem.pos, text)
+	}
+}
+
+// fail records a problem which can not be ignored even in ParseLenient mode.
+func (l *loader) fail(err *ParseError) {
+	if l.Mode == ParseLenient {
+		l.fatal = err
+	} else {
+		l.errs = append(l.errs, err)
+	}
+}
+
+func invalidUTF8Pos(b []byte) int {
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return i + 1
 		}
 
-		//  找到第一个等号的位置
-		end := bytes.IndexFunc(l[pos+1:], func(r rune) bool { return r == '=' || r == ':' })
+		i += size
+	}
 
-		var (
-			typo       byte = '=' //  没有=，说明该配置项只有key
-			key, value []byte
-		)
+	return 0
+}
 
-		if end == -1 {
-			key = bytes.TrimRightFunc(l[pos:], unicode.IsSpace)
-		} else {
-			key = bytes.TrimRightFunc(l[pos:pos+1+end], unicode.IsSpace)
-			value = bytes.TrimSpace(l[pos+1+end+1:])
-			typo = l[pos+1+end]
+func (l *loader) parseLine(s string) {
+	p := l.doc
+
+	//  找到第一个非空白字符
+	pos := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsSpace(r) })
+
+	//  遇到空行或者空白行
+	if pos == -1 {
+		p.lines.PushBack(&line{typo: ' ', value: "", raw: s, eol: l.eol, pos: l.pos})
+		return
+	}
+
+	//  遇到注释行
+	if isComment(s[pos]) {
+		p.lines.PushBack(&line{typo: s[pos], value: s, raw: s, eol: l.eol, pos: l.pos})
+		return
+	}
+
+	//  以分隔符开头,key为空
+	if s[pos] == '=' || s[pos] == ':' {
+		l.report(l.lineNo, pos+1, s, "empty key")
+	}
+
+	elem := &line{typo: '=', indent: s[:pos], raw: s, eol: l.eol, pos: l.pos} //  没有=，说明该配置项只有key
+
+	//  找到第一个等号的位置
+	end := strings.IndexFunc(s[pos+1:], func(r rune) bool { return r == '=' || r == ':' })
+	if end == -1 {
+		elem.key = strings.TrimRightFunc(s[pos:], unicode.IsSpace)
+		elem.trail = s[pos+len(elem.key):]
+	} else {
+		sepPos := pos + 1 + end
+		elem.typo = s[sepPos]
+		elem.key = strings.TrimRightFunc(s[pos:sepPos], unicode.IsSpace)
+		rest := s[sepPos+1:]
+		elem.value = strings.TrimSpace(rest)
+		valuePos := sepPos + 1 + len(rest) - len(strings.TrimLeftFunc(rest, unicode.IsSpace))
+		elem.sep = s[pos+len(elem.key) : valuePos]
+		elem.trail = s[valuePos+len(elem.value):]
+	}
+
+	l.addProperty(elem, pos+1, s)
+}
+
+// parseJavaLine parses a natural line in the java dialect,
+// the following continuation lines are read by scan.
+func (l *loader) parseJavaLine(s string) {
+	p := l.doc
+	trimmed := trimJavaSpace(s)
+
+	//  遇到空行或者空白行
+	if trimmed == "" {
+		p.lines.PushBack(&line{typo: ' ', value: "", raw: s, eol: l.eol, pos: l.pos})
+		return
+	}
+
+	//  遇到注释行,注释行没有续行
+	if isComment(trimmed[0]) {
+		p.lines.PushBack(&line{typo: trimmed[0], value: s, raw: s, eol: l.eol, pos: l.pos})
+		return
+	}
+
+	raw, logical, start := s, trimmed, l.pos
+	l.checkEscapes(s)
+
+	//  以奇数个反斜杠结尾的行需要与下一行拼接
+	for needsContinuation(logical) {
+		logical = logical[:len(logical)-1]
+		eol := l.eol
+
+		if !l.scan() {
+			break
 		}
 
-		elem := &line{typo: typo, key: string(key), value: string(value)}
-		doc.props[string(key)] = doc.lines.PushBack(elem)
+		raw += eol + l.cur
+		logical += trimJavaSpace(l.cur)
+		l.checkEscapes(l.cur)
+	}
+
+	key, value, sep := splitJava(logical)
+	if key == "" {
+		l.report(start.Line, len(s)-len(trimmed)+1, s, "empty key")
+	}
+
+	typo := byte('=')
+	if sep == ':' {
+		typo = sep
 	}
 
-	return doc, scanner.Err()
+	elem := &line{typo: typo, key: unescapeJava(key), value: unescapeJava(value),
+		indent: s[:len(s)-len(trimmed)], sep: logical[len(key) : len(logical)-len(value)], raw: raw, eol: l.eol, pos: start}
+	l.addProperty(elem, len(s)-len(trimmed)+1, s)
+}
+
+// checkEscapes reports the malformed \uXXXX sequences in the current natural line.
+func (l *loader) checkEscapes(s string) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			continue
+		}
+
+		if i++; s[i] == 'u' {
+			if _, ok := parseUnicodeEscape(s[i+1:]); !ok {
+				l.report(l.lineNo, i, s, `malformed \uXXXX encoding`)
+			}
+		}
+	}
 }
@@ -0,0 +1,408 @@
+package properties
+
+import (
+	"container/list"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Marshal creates a document from v, which is a struct, a map or a pointer to them.
+//
+// Each field becomes a property keyed by its tag value or its name, the fields tagged "-" are skipped,
+// and the fields with the ",omitempty" tag option are skipped if they are empty,
+// the option applies to the field itself only, not the elements of a slice or a map.
+// The nested structs become the dotted prefixes like db.host, but the embedded structs do not.
+// The slices become the indexed keys like hosts.0 and the maps become the keys like labels.name in sorted order.
+// The values are formatted to be parsed by the typed getters, the encoding.TextMarshaler is used if implemented,
+// and the []byte is encoded in base64 like encoding/json.
+// The comment tag like `comment:"the host"` becomes the comment lines above the key.
+func Marshal(v interface{}, tag string) (*Doc, error) {
+	doc := New()
+
+	err := walkFields(reflect.ValueOf(v), tag, func(e fieldEntry) {
+		if e.container || e.omitEmpty && e.empty {
+			return
+		}
+
+		doc.Set(e.key, e.value)
+
+		if e.comment != "" {
+			doc.Comment(e.key, e.comment)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// UpdateFrom writes the field values of v back to the doc, the keys and the values are same as Marshal.
+//
+// The existing keys keep their positions, comments and layout, the new keys are inserted after
+// the siblings sharing the longest dotted prefix like db.host for db.port, or appended if there is none.
+// The fields with the ",omitempty" tag option are left alone if they are empty, so are the fields
+// tagged like `default:"8080"` with the default values, their keys are neither added nor overwritten.
+// The keys of the slices and the maps which are no longer in v, like hosts.2 after shrinking, are deleted.
+// The doc is not modified if an error is returned.
+func (p *Doc) UpdateFrom(v interface{}, tag string) error {
+	_, err := p.Update(func(tx *Tx) error {
+		u := &fieldUpdater{doc: tx.doc, keys: make(map[string]bool)}
+		if err := walkFields(reflect.ValueOf(v), tag, u.update); err != nil {
+			return err
+		}
+
+		u.deleteStale()
+
+		return nil
+	})
+
+	return err
+}
+
+// fieldUpdater updates the doc by the walked fields.
+type fieldUpdater struct {
+	doc        *Doc
+	keys       map[string]bool //  遍历到的key
+	containers []string        //  遍历到的slice和map的key
+}
+
+func (u *fieldUpdater) update(e fieldEntry) {
+	if e.container {
+		u.containers = append(u.containers, e.key)
+		return
+	}
+
+	p := u.doc
+	u.keys[e.key] = true
+	p.beforeWrite()
+
+	if e.omitEmpty && e.empty || e.hasDef && e.value == e.def {
+		return
+	}
+
+	if old, ok := p.GetRaw(e.key); ok {
+		if old != e.value {
+			p.Set(e.key, e.value)
+		}
+
+		return
+	}
+
+	if sibling := p.sibling(e.key); sibling != nil {
+		p.insertAfter(sibling, e.key, e.value)
+	} else {
+		p.Set(e.key, e.value)
+	}
+
+	if e.comment != "" {
+		p.Comment(e.key, e.comment)
+	}
+}
+
+// deleteStale deletes the keys under the slices and the maps not walked.
+func (u *fieldUpdater) deleteStale() {
+	for _, key := range u.doc.keys() {
+		if u.keys[key] {
+			continue
+		}
+
+		for _, c := range u.containers {
+			if strings.HasPrefix(key, c+".") {
+				u.doc.Del(key)
+				break
+			}
+		}
+	}
+}
+
+// sibling gives the last property line sharing the longest dotted prefix with the key, or nil if there is none.
+func (p Doc) sibling(key string) *list.Element {
+	var (
+		sibling *list.Element
+		longest int
+	)
+
+	segments := strings.Split(key, ".")
+
+	for e := p.lines.Front(); e != nil; e = e.Next() {
+		l := e.Value.(*line)
+		if !l.isProperty() {
+			continue
+		}
+
+		n := 0
+		for i, s := range strings.Split(l.key, ".") {
+			if i >= len(segments)-1 || s != segments[i] {
+				break
+			}
+
+			n++
+		}
+
+		if n > 0 && n >= longest {
+			sibling, longest = e, n
+		}
+	}
+
+	return sibling
+}
+
+// insertAfter inserts a new property line after the element,
+// beforeWrite should be called before getting the element.
+func (p *Doc) insertAfter(e *list.Element, key, value string) {
+	p.props[key] = p.lines.InsertAfter(&line{typo: '=', key: key, value: value}, e)
+}
+
+// fieldEntry is a property walked from a struct.
+type fieldEntry struct {
+	key, value string
+	comment    string //  comment标签
+	omitEmpty  bool   //  omitempty标签选项
+	empty      bool   //  值为空(零值)
+	def        string //  default标签
+	hasDef     bool
+	container  bool //  slice或map,其元素是以key为前缀的其他项
+}
+
+// walkFields walks the properties of v in order.
+func walkFields(v reflect.Value, tag string, f func(fieldEntry)) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+		return fmt.Errorf("unsupported type %v, a struct or a map required", v.Kind())
+	}
+
+	w := &fieldWalker{tag: tag, f: f}
+
+	return w.walk(v, fieldEntry{})
+}
+
+type fieldWalker struct {
+	tag string
+	f   func(fieldEntry)
+}
+
+// walk walks the value, the entry holds the key, the comment and the tag options of it.
+func (w *fieldWalker) walk(v reflect.Value, e fieldEntry) error {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if value, ok, err := formatValue(v); ok || err != nil {
+		e.value, e.empty = value, isEmptyValue(v)
+		w.f(e)
+
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return w.walkStruct(v, e)
+	case reflect.Slice, reflect.Array:
+		w.container(e)
+
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(v.Index(i), w.child(&e, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		w.container(e)
+
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+
+		for i, k := range keys {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+
+		sort.Sort(byName{names, keys})
+
+		for i, k := range keys {
+			if err := w.walk(v.MapIndex(k), w.child(&e, names[i])); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %v of %s", v.Type(), e.key)
+	}
+
+	return nil
+}
+
+func (w *fieldWalker) walkStruct(v reflect.Value, e fieldEntry) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { //  忽略未导出的字段
+			continue
+		}
+
+		name, opts := parseFieldTag(sf.Tag.Get(w.tag))
+		if name == "-" {
+			continue
+		}
+
+		child := e
+		e.comment = ""
+
+		//  内嵌的结构体没有前缀
+		if !sf.Anonymous || name != "" || indirectType(sf.Type).Kind() != reflect.Struct {
+			if name == "" {
+				name = sf.Name
+			}
+
+			child = w.child(&child, name)
+		}
+
+		if comment := sf.Tag.Get("comment"); comment != "" && child.comment != "" {
+			child.comment += "\n" + comment
+		} else if comment != "" {
+			child.comment = comment
+		}
+
+		child.omitEmpty = hasTagOption(opts, "omitempty")
+		child.def, child.hasDef = sf.Tag.Lookup("default")
+
+		if err := w.walk(v.Field(i), child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// container reports the slice or the map, except the top level one.
+func (w *fieldWalker) container(e fieldEntry) {
+	if e.key != "" {
+		w.f(fieldEntry{key: e.key, container: true})
+	}
+}
+
+// child creates the entry of the child, the comment is given to the first child only.
+func (w *fieldWalker) child(e *fieldEntry, name string) fieldEntry {
+	child := *e
+	if child.key != "" {
+		name = child.key + "." + name
+	}
+
+	child.key = name
+	child.omitEmpty, child.def, child.hasDef = false, "", false //  标签选项只作用于字段本身
+	e.comment = ""
+
+	return child
+}
+
+// parseFieldTag parses the tag value like "name,omitempty".
+func parseFieldTag(tag string) (name, opts string) {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+
+	return tag, ""
+}
+
+// hasTagOption tells whether the comma separated options contain the option.
+func hasTagOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formatValue formats the scalar value, returns false if v is not a scalar.
+func formatValue(v reflect.Value) (string, bool, error) {
+	if v.CanInterface() {
+		m, ok := v.Interface().(encoding.TextMarshaler)
+		if !ok && v.CanAddr() {
+			m, ok = v.Addr().Interface().(encoding.TextMarshaler)
+		}
+
+		if ok {
+			b, err := m.MarshalText()
+			return string(b), true, err
+		}
+
+		if d, ok := v.Interface().(time.Duration); ok {
+			return d.String(), true, nil
+		}
+	}
+
+	//  []byte与encoding/json一致,使用base64编码
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return base64.StdEncoding.EncodeToString(v.Bytes()), true, nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64), true, nil
+	}
+
+	return "", false, nil
+}
+
+// isEmptyValue tells whether the scalar value is empty like encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	}
+
+	return v.CanInterface() && reflect.DeepEqual(reflect.Zero(v.Type()).Interface(), v.Interface())
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// byName sorts the map keys by their names.
+type byName struct {
+	names []string
+	keys  []reflect.Value
+}
+
+func (b byName) Len() int           { return len(b.names) }
+func (b byName) Less(i, j int) bool { return b.names[i] < b.names[j] }
+func (b byName) Swap(i, j int) {
+	b.names[i], b.names[j] = b.names[j], b.names[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
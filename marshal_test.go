@@ -0,0 +1,202 @@
+package properties
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type marshalDB struct {
+	Host string `prop:"host" comment:"the database host"`
+	Port int    `prop:"port"`
+}
+
+type marshalBase struct {
+	Name string `prop:"name"`
+}
+
+type marshalConfig struct {
+	marshalBase
+	DB       marshalDB         `prop:"db" comment:"database"`
+	Debug    bool              `prop:"debug"`
+	Ratio    float64           `prop:"ratio"`
+	Timeout  time.Duration     `prop:"timeout"`
+	IP       net.IP            `prop:"ip"`
+	Hosts    []string          `prop:"hosts" comment:"the hosts"`
+	Labels   map[string]string `prop:"labels"`
+	Replicas []*marshalDB      `prop:"replicas"`
+	Secret   string            `prop:"-"`
+	Empty    string            `prop:"empty,omitempty"`
+	Max      uint64
+	Ptr      *int `prop:"ptr"`
+	ignored  string
+}
+
+func TestMarshal(t *testing.T) {
+	that := assert.New(t)
+
+	c := marshalConfig{
+		marshalBase: marshalBase{Name: "app"},
+		DB:          marshalDB{Host: "localhost", Port: 3306},
+		Debug:       true,
+		Ratio:       0.5,
+		Timeout:     1500 * time.Millisecond,
+		IP:          net.ParseIP("127.0.0.1"),
+		Hosts:       []string{"a", "b"},
+		Labels:      map[string]string{"z": "1", "a": "2"},
+		Replicas:    []*marshalDB{{Host: "r1", Port: 1}},
+		Secret:      "secret",
+		Max:         18446744073709551615,
+		ignored:     "x",
+	}
+
+	doc, err := Marshal(&c, "prop")
+	that.Nil(err)
+	that.Equal(`name=app
+#database
+#the database host
+db.host=localhost
+db.port=3306
+debug=true
+ratio=0.5
+timeout=1.5s
+ip=127.0.0.1
+#the hosts
+hosts.0=a
+hosts.1=b
+labels.a=2
+labels.z=1
+#the database host
+replicas.0.host=r1
+replicas.0.port=1
+Max=18446744073709551615
+`, doc.String())
+
+	that.True(doc.Bool("debug"))
+	that.Equal(0.5, doc.Float64("ratio"))
+	that.Equal(uint64(18446744073709551615), doc.Uint64("Max"))
+
+	var db marshalDB
+
+	d, _ := Marshal(marshalDB{Host: "h", Port: 1}, "prop")
+	that.Nil(d.Populate(&db, "prop"))
+	that.Equal(marshalDB{Host: "h", Port: 1}, db)
+
+	d, _ = Marshal(map[int]string{2: "b", 1: "a"}, "")
+	that.Equal("1=a\n2=b\n", d.String())
+
+	_, err = Marshal("x", "prop")
+	that.NotNil(err)
+
+	_, err = Marshal(struct{ C chan int }{}, "prop")
+	that.NotNil(err)
+}
+
+type updateConfig struct {
+	DB struct {
+		Host string `prop:"host"`
+		Port int    `prop:"port" default:"3306"`
+		Pool struct {
+			Max  int `prop:"max"`
+			Idle int `prop:"idle" comment:"idle connections"`
+		} `prop:"pool"`
+	} `prop:"db"`
+	Name    string    `prop:"name,omitempty"`
+	Hosts   []string  `prop:"hosts"`
+	Debug   bool      `prop:"debug"`
+	Invalid *chan int `prop:"invalid"`
+}
+
+func TestUpdateFrom(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# database\ndb.host = localhost\ndb.pool.max = 10\n\n# web\nweb.port = 80\n"
+	doc, _ := LoadString(text)
+	snapshot := doc.Snapshot()
+
+	var c updateConfig
+
+	c.DB.Host = "db.example.com"
+	c.DB.Port = 3306
+	c.DB.Pool.Max = 10
+	c.DB.Pool.Idle = 2
+	c.Hosts = []string{"a", "b"}
+	c.Debug = true
+
+	that.Nil(doc.UpdateFrom(&c, "prop"))
+	that.Equal("# database\ndb.host = db.example.com\ndb.pool.max = 10\n#idle connections\ndb.pool.idle=2\n\n"+
+		"# web\nweb.port = 80\nhosts.0=a\nhosts.1=b\ndebug=true\n", doc.String())
+	that.Equal(text, snapshot.String())
+
+	c.DB.Port = 3307
+	c.Name = "app"
+	c.Hosts = []string{"a", "c", "d"}
+
+	that.Nil(doc.UpdateFrom(&c, "prop"))
+	that.Equal("# database\ndb.host = db.example.com\ndb.pool.max = 10\n#idle connections\ndb.pool.idle=2\ndb.port=3307\n\n"+
+		"# web\nweb.port = 80\nhosts.0=a\nhosts.1=c\nhosts.2=d\ndebug=true\nname=app\n", doc.String())
+
+	before := doc.String()
+	ch := make(chan int)
+	c.Invalid = &ch
+	c.Name = "changed"
+	that.NotNil(doc.UpdateFrom(&c, "prop"))
+	that.Equal(before, doc.String(), "rollback on error")
+
+	//  空值的omitempty字段和默认值的字段不覆盖已存在的key
+	c.Invalid = nil
+	c.DB.Port = 3306
+	c.Name = ""
+	c.Debug = false
+	that.Nil(doc.UpdateFrom(&c, "prop"))
+	that.Equal("3307", doc.Str("db.port"))
+	that.Equal("app", doc.Str("name"))
+	that.False(doc.Bool("debug"))
+
+	secrets, _ := LoadString("password=secret\nport=9090\n")
+	that.Nil(secrets.UpdateFrom(&struct {
+		Password string `prop:"password,omitempty"`
+		Port     int    `prop:"port" default:"80"`
+	}{Port: 80}, "prop"))
+	that.Equal("password=secret\nport=9090\n", secrets.String())
+}
+
+func TestUpdateFromShrink(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# hosts\nhosts.0=a\nhosts.1=b\nhosts.2=c\nlabels.x=1\nlabels.y=2\nother=1\n" +
+		"replicas.0.host=r0\nreplicas.1.host=r1\n"
+	doc, _ := LoadString(text)
+
+	c := struct {
+		Hosts    []string          `prop:"hosts"`
+		Labels   map[string]string `prop:"labels"`
+		Replicas []struct {
+			Host string `prop:"host"`
+		} `prop:"replicas"`
+	}{Hosts: []string{"x"}, Labels: map[string]string{"y": "2"}}
+	c.Replicas = append(c.Replicas, struct {
+		Host string `prop:"host"`
+	}{Host: "r0"})
+
+	that.Nil(doc.UpdateFrom(&c, "prop"))
+	that.Equal("# hosts\nhosts.0=x\nlabels.y=2\nother=1\nreplicas.0.host=r0\n", doc.String())
+}
+
+func TestMarshalOptions(t *testing.T) {
+	that := assert.New(t)
+
+	v := struct {
+		Data   []byte   `prop:"data"`
+		S      []string `prop:"s,omitempty"`
+		Empty  []byte   `prop:"empty,omitempty"`
+		Nearly string   `prop:"nearly,omitemptyx"`
+		Omit   string   `prop:"omit,string,omitempty"`
+	}{Data: []byte("hi"), S: []string{""}, Empty: []byte{}}
+
+	doc, err := Marshal(v, "prop")
+	that.Nil(err)
+	that.Equal("data=aGk=\ns.0=\nnearly=\n", doc.String())
+}
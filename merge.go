@@ -0,0 +1,95 @@
+package properties
+
+import (
+	"strings"
+)
+
+// MergeOptions defines the options of Merge3.
+type MergeOptions struct {
+	// Markers specifies whether to write the Git-style conflict markers as the comment lines
+	// before the conflicting line, or at the end if the line is deleted by ours.
+	Markers bool
+}
+
+// MergeOption defines the option function of Merge3.
+type MergeOption func(*MergeOptions)
+
+// WithConflictMarkers specifies whether to write the conflict markers.
+func WithConflictMarkers(markers bool) MergeOption {
+	return func(o *MergeOptions) { o.Markers = markers }
+}
+
+// Merge3 merges the changes from base to theirs into ours by keys,
+// the comments and the order of the lines are kept as ours, and the keys added by theirs are appended.
+//
+// A conflict is reported when both sides changed a key differently, the value of ours is kept for it.
+func Merge3(base, ours, theirs *Doc, options ...MergeOption) (*Doc, []Conflict) {
+	opts := &MergeOptions{}
+	for _, f := range options {
+		f(opts)
+	}
+
+	merged := ours.Clone()
+
+	var conflicts []Conflict
+
+	for _, e := range NewPatch(base, theirs) {
+		value, exist := merged.GetRaw(e.Key)
+		_, baseExist := base.GetRaw(e.Key)
+		theirsExist := e.ChangeType != Removed
+
+		switch {
+		case exist == theirsExist && value == e.RightValue: //  双方的修改相同
+		case exist == baseExist && value == e.LeftValue: //  ours未修改
+			if theirsExist {
+				merged.Set(e.Key, e.RightValue)
+			} else {
+				merged.Del(e.Key)
+			}
+		default:
+			c := Conflict{Key: e.Key, Base: e.LeftValue, Ours: value, Theirs: e.RightValue,
+				Reason: mergeReason(baseExist, exist, theirsExist)}
+			conflicts = append(conflicts, c)
+
+			if opts.Markers {
+				merged.markConflict(c, exist, theirsExist)
+			}
+		}
+	}
+
+	return merged, conflicts
+}
+
+func mergeReason(baseExist, oursExist, theirsExist bool) string {
+	switch {
+	case !baseExist:
+		return "both added"
+	case !oursExist:
+		return "deleted by ours, modified by theirs"
+	case !theirsExist:
+		return "modified by ours, deleted by theirs"
+	default:
+		return "both modified"
+	}
+}
+
+// markConflict writes the conflict markers as the comment lines.
+func (p *Doc) markConflict(c Conflict, oursExist, theirsExist bool) {
+	markers := []string{" <<<<<<< ours"}
+	if oursExist {
+		markers = append(markers, " "+c.Key+"="+c.Ours)
+	}
+
+	markers = append(markers, " =======")
+	if theirsExist {
+		markers = append(markers, " "+c.Key+"="+c.Theirs)
+	}
+
+	markers = append(markers, " >>>>>>> theirs")
+
+	if text := strings.Join(markers, "\n"); oursExist {
+		p.Comment(c.Key, text)
+	} else {
+		p.appendComments(text)
+	}
+}
@@ -0,0 +1,45 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	that := assert.New(t)
+
+	base, _ := LoadString("a=1\nb=2\nc=3\nd=4\ne=5\nf=6\n")
+	ours, _ := LoadString("# ours\nf=6\na=10\nb=2\nc=30\nd=40\ne=5\ny=1\n")
+	theirs, _ := LoadString("a=10\nb=20\nc=31\ne=5\nf=6\nx=1\ny=2\n")
+
+	merged, conflicts := Merge3(base, ours, theirs)
+	that.Equal("# ours\nf=6\na=10\nb=20\nc=30\nd=40\ne=5\ny=1\nx=1\n", merged.String())
+	that.Equal([]Conflict{
+		{Key: "c", Base: "3", Ours: "30", Theirs: "31", Reason: "both modified"},
+		{Key: "d", Base: "4", Ours: "40", Reason: "modified by ours, deleted by theirs"},
+		{Key: "y", Ours: "1", Theirs: "2", Reason: "both added"},
+	}, conflicts)
+
+	that.Equal("# ours\nf=6\na=1\nb=2\nc=3\nd=4\ne=5\ny=1\n", func() string {
+		o, _ := LoadString("# ours\nf=6\na=1\nb=2\nc=3\nd=4\ne=5\ny=1\n")
+		m, _ := Merge3(base, o, base)
+		return m.String()
+	}(), "nothing changed by theirs")
+
+	that.Equal("# ours\nf=6\na=10\nb=2\nc=30\nd=40\ne=5\ny=1\n", ours.String(), "ours is not modified")
+}
+
+func TestMerge3Markers(t *testing.T) {
+	that := assert.New(t)
+
+	base, _ := LoadString("a=1\nb=2\nc=3\n")
+	ours, _ := LoadString("a=10\nc=3\n")
+	theirs, _ := LoadString("a=11\nb=20\nc=3\n")
+
+	merged, conflicts := Merge3(base, ours, theirs, WithConflictMarkers(true))
+	that.Len(conflicts, 2)
+	that.Equal("deleted by ours, modified by theirs", conflicts[1].Reason)
+	that.Equal("# <<<<<<< ours\n# a=10\n# =======\n# a=11\n# >>>>>>> theirs\na=10\nc=3\n"+
+		"# <<<<<<< ours\n# =======\n# b=20\n# >>>>>>> theirs\n", merged.String())
+}
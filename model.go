@@ -8,17 +8,40 @@ type line struct {
 	//  #   注释行
 	//  !   注释行
 	//  ' ' 空白行或者空行
+	//  @   包含指令行
 	//  =   等号分隔的属性行
 	//  :   冒号分隔的属性行
-	typo  byte   //  行类型
-	value string //  值,如果是注释注释引导符也包含在内。
-	key   string //  如果是属性行这里表示属性的key
+	typo  byte     //  行类型
+	value string   //  值,如果是注释注释引导符也包含在内。
+	key   string   //  如果是属性行这里表示属性的key
+	raw   string   //  原始文本(含续行),为空表示保存时需要重新生成
+	eol   string   //  原始的行尾,为空时使用文档的行尾
+	pos   Position //  行在源中的位置,新增的行为零值
+
+	included bool //  从包含的文件中加载的行,保存时忽略
+
+	//  以下为属性行的布局,用于修改值后重新生成该行
+	indent string //  key前面的缩进
+	sep    string //  key和value之间的分隔符,含两侧的空白
+	trail  string //  value后面的空白
 }
 
 // Doc The properties document in memory.
 type Doc struct {
-	lines *list.List
-	props map[string]*list.Element
+	lines   *list.List
+	props   map[string]*list.Element
+	dialect Dialect
+
+	encoding Encoding //  保存时使用的字符集,与加载时的一致
+	bom      bool     //  保存时是否写入BOM
+	eol      string   //  新增行使用的行尾,与加载的第一行一致
+
+	noFinalEOL  bool //  原文档的最后一行没有行尾
+	noExpansion bool //  Get时不展开${...}引用
+
+	profiles map[string]string //  profile文件 -> profile名称,见LoadProfiles
+
+	shared bool //  lines和props与快照共享,修改前需要复制,见Snapshot
 }
 
 func isComment(typo byte) bool {
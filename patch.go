@@ -0,0 +1,144 @@
+package properties
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// Patch is the list of changes between two documents, which can be reviewed and applied to another document.
+//
+// It is marshaled to JSON as an array of {"type", "key", "left", "right"} objects,
+// or to the text format by String, see ParsePatch.
+type Patch []DiffEvent
+
+// Conflict is a change can not be applied or merged, see Patch.Apply and Merge3.
+type Conflict struct {
+	Key    string
+	Base   string //  变更前的值
+	Ours   string //  目标文档中的当前值
+	Theirs string //  变更后的值
+	Reason string
+}
+
+// String gives the description of the conflict.
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: %s (base %q, ours %q, theirs %q)", c.Key, c.Reason, c.Base, c.Ours, c.Theirs)
+}
+
+// NewPatch creates the patch from the changes(except Same) of Diff(l, r).
+func NewPatch(l, r *Doc) Patch {
+	return changes(l, r)
+}
+
+// nolint gochecknoglobals
+var patchOps = map[ChangeType]string{Modified: "~", Added: "+", Removed: "-"}
+
+// String gives the patch in the text format, one change per line:
+//
+//	~ key left right
+//	+ key  right
+//	- key left
+//
+// The fields are separated by a tab and escaped like the java properties,
+// so the tabs and the line breaks in the values are kept.
+func (p Patch) String() string {
+	var b strings.Builder
+
+	for _, e := range p {
+		b.WriteString(patchOps[e.ChangeType])
+
+		for _, field := range []string{escapeJava(e.Key, true), escapeJava(e.LeftValue, false), escapeJava(e.RightValue, false)} {
+			b.WriteByte('\t')
+			b.WriteString(field)
+		}
+
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// ParsePatch parses the patch in the text format given by Patch.String.
+// The empty lines and the comment lines starting with '#' are ignored.
+func ParsePatch(text string) (Patch, error) {
+	var patch Patch
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		s := strings.TrimSuffix(scanner.Text(), "\r")
+		if s == "" || s[0] == '#' {
+			continue
+		}
+
+		fields := strings.Split(s, "\t")
+		if len(fields) != 4 {
+			return nil, &ParseError{Line: lineNo, Column: 1, Text: s, Reason: "expect 4 tab separated fields"}
+		}
+
+		typ, ok := patchType(fields[0])
+		if !ok {
+			return nil, &ParseError{Line: lineNo, Column: 1, Text: s, Reason: "unknown change " + fields[0]}
+		}
+
+		patch = append(patch, DiffEvent{ChangeType: typ,
+			Key: unescapeJava(fields[1]), LeftValue: unescapeJava(fields[2]), RightValue: unescapeJava(fields[3])})
+	}
+
+	return patch, scanner.Err()
+}
+
+func patchType(op string) (ChangeType, bool) {
+	for typ, o := range patchOps {
+		if o == op {
+			return typ, true
+		}
+	}
+
+	return 0, false
+}
+
+// Apply applies the patch to the doc, the comments and the order of the doc are kept,
+// and the added keys are appended to the end.
+//
+// A change conflicts if the current value of the doc differs from its LeftValue,
+// the conflicts are skipped and returned, the other changes are still applied.
+// The changes already applied to the doc are ignored.
+func (p Patch) Apply(doc *Doc) []Conflict {
+	var conflicts []Conflict
+
+	for _, e := range p {
+		ours, exist := doc.GetRaw(e.Key)
+		c := Conflict{Key: e.Key, Base: e.LeftValue, Ours: ours, Theirs: e.RightValue}
+
+		switch e.ChangeType {
+		case Added, Modified:
+			switch {
+			case exist && ours == e.RightValue:
+			case e.ChangeType == Added && exist:
+				c.Reason = "added with a different value"
+			case e.ChangeType == Modified && !exist:
+				c.Reason = "missing"
+			case e.ChangeType == Modified && ours != e.LeftValue:
+				c.Reason = "modified with a different value"
+			default:
+				doc.Set(e.Key, e.RightValue)
+			}
+		case Removed:
+			switch {
+			case !exist:
+			case ours != e.LeftValue:
+				c.Reason = "modified with a different value"
+			default:
+				doc.Del(e.Key)
+			}
+		}
+
+		if c.Reason != "" {
+			conflicts = append(conflicts, c)
+		}
+	}
+
+	return conflicts
+}
@@ -0,0 +1,79 @@
+package properties
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatch(t *testing.T) {
+	that := assert.New(t)
+
+	staging, _ := LoadString("a=1\nb=2\nc=3\n")
+	changed, _ := LoadString("a=10\nb=2\nd= x\\ty\n")
+
+	patch := NewPatch(staging, changed)
+	that.Equal(Patch{
+		{ChangeType: Modified, Key: "a", LeftValue: "1", RightValue: "10"},
+		{ChangeType: Added, Key: "d", RightValue: "x\\ty"},
+		{ChangeType: Removed, Key: "c", LeftValue: "3"},
+	}, patch)
+
+	text := patch.String()
+	that.Equal("~\ta\t1\t10\n+\td\t\tx\\\\ty\n-\tc\t3\t\n", text)
+
+	parsed, err := ParsePatch("# reviewed\r\n\n" + text)
+	that.Nil(err)
+	that.Equal(patch, parsed)
+
+	b, err := json.Marshal(patch)
+	that.Nil(err)
+	that.Equal(`[{"type":"modified","key":"a","left":"1","right":"10"},`+
+		`{"type":"added","key":"d","left":"","right":"x\\ty"},`+
+		`{"type":"removed","key":"c","left":"3","right":""}]`, string(b))
+
+	var unmarshaled Patch
+	that.Nil(json.Unmarshal(b, &unmarshaled))
+	that.Equal(patch, unmarshaled)
+
+	that.NotNil(json.Unmarshal([]byte(`[{"type":"x"}]`), &unmarshaled))
+
+	_, err = ParsePatch("~\ta\t1")
+	that.EqualError(err, "<input>:1:1: expect 4 tab separated fields: \"~\\ta\\t1\"")
+
+	_, err = ParsePatch("*\ta\t1\t2")
+	that.NotNil(err)
+
+	escaped := Patch{{ChangeType: Added, Key: "k e=y", RightValue: " v\t1\n2"}}
+	parsed, _ = ParsePatch(escaped.String())
+	that.Equal(escaped, parsed)
+}
+
+func TestPatchApply(t *testing.T) {
+	that := assert.New(t)
+
+	patch := Patch{
+		{ChangeType: Modified, Key: "a", LeftValue: "1", RightValue: "10"},
+		{ChangeType: Modified, Key: "b", LeftValue: "2", RightValue: "20"},
+		{ChangeType: Modified, Key: "m", LeftValue: "1", RightValue: "2"},
+		{ChangeType: Added, Key: "d", RightValue: "4"},
+		{ChangeType: Added, Key: "e", RightValue: "5"},
+		{ChangeType: Added, Key: "f", RightValue: "6"},
+		{ChangeType: Removed, Key: "c", LeftValue: "3"},
+		{ChangeType: Removed, Key: "g", LeftValue: "7"},
+		{ChangeType: Removed, Key: "x", LeftValue: "1"},
+	}
+
+	prod, _ := LoadString("# prod\n\nc=3\nb=22\n# a\na=1\ne=5\nf=66\ng=77\n")
+
+	conflicts := patch.Apply(prod)
+	that.Equal([]Conflict{
+		{Key: "b", Base: "2", Ours: "22", Theirs: "20", Reason: "modified with a different value"},
+		{Key: "m", Base: "1", Theirs: "2", Reason: "missing"},
+		{Key: "f", Ours: "66", Theirs: "6", Reason: "added with a different value"},
+		{Key: "g", Base: "7", Ours: "77", Reason: "modified with a different value"},
+	}, conflicts)
+	that.Equal("# prod\n\nb=22\n# a\na=10\ne=5\nf=66\ng=77\nd=4\n", prod.String())
+	that.Equal(`b: modified with a different value (base "2", ours "22", theirs "20")`, conflicts[0].String())
+}
@@ -0,0 +1,59 @@
+package properties
+
+import (
+	"fmt"
+)
+
+// Position describes where a line is defined in the source.
+type Position struct {
+	Source string // 源名称,由LoadFile或WithSource指定
+	Line   int    // 行号,从1开始,不是从源加载的行为0
+	Offset int    // 行首在源中的字节偏移,包括BOM,按源的编码计算
+}
+
+// IsValid tells whether the position is loaded from a source.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String gives the position like app.properties:132,
+// or the source only like env:APP_DB_HOST if there is no line.
+func (p Position) String() string {
+	if !p.IsValid() {
+		if p.Source != "" {
+			return p.Source
+		}
+
+		return "-"
+	}
+
+	source := p.Source
+	if source == "" {
+		source = "<input>"
+	}
+
+	return fmt.Sprintf("%s:%d", source, p.Line)
+}
+
+// Position retrieves the position where the key is defined.
+//
+// If the line is not exist, the exist is false.
+// The position of a line added after loading is the zero value.
+func (p Doc) Position(key string) (pos Position, exist bool) {
+	if e, ok := p.props[key]; ok {
+		return e.Value.(*line).pos, true
+	}
+
+	return Position{}, false
+}
+
+// AcceptWithPosition traverses every line of the document like Accept,
+// and gives the position of each line as well.
+func (p Doc) AcceptWithPosition(f func(typo byte, value, key string, pos Position) bool) {
+	for e := p.lines.Front(); e != nil; e = e.Next() {
+		elem := e.Value.(*line)
+		if continues := f(elem.typo, elem.value, elem.key, elem.pos); !continues {
+			return
+		}
+	}
+}
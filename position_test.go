@@ -0,0 +1,65 @@
+package properties
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPosition(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadString("# c\r\na=1\r\nb = 2 \\\r\n 3\r\nc=4", WithSource("app.properties"), WithDialect(DialectJava))
+
+	pos, ok := doc.Position("a")
+	that.True(ok)
+	that.Equal(Position{Source: "app.properties", Line: 2, Offset: 5}, pos)
+	that.Equal("app.properties:2", pos.String())
+
+	pos, _ = doc.Position("c")
+	that.Equal(Position{Source: "app.properties", Line: 5, Offset: 23}, pos)
+
+	_, ok = doc.Position("NOT-EXIST")
+	that.False(ok)
+
+	doc.Set("d", "5")
+	pos, _ = doc.Position("d")
+	that.False(pos.IsValid())
+	that.Equal("-", pos.String())
+
+	var lines []int
+
+	doc.AcceptWithPosition(func(typo byte, value, key string, pos Position) bool {
+		lines = append(lines, pos.Line)
+		return true
+	})
+
+	that.Equal([]int{1, 2, 3, 5, 0}, lines)
+}
+
+func TestPositionOffset(t *testing.T) {
+	that := assert.New(t)
+
+	for _, c := range []struct {
+		input    []byte
+		encoding Encoding
+		offset   int
+	}{
+		{[]byte("\xEF\xBB\xBFa=1\nb=2\n"), EncodingUTF8, 7},
+		{[]byte("a=\xE9\nb=2\n"), EncodingISO88591, 4},
+		{[]byte("\xFF\xFEa\x00=\x00\xE9\x00\n\x00b\x00=\x002\x00"), EncodingUTF16, 10},
+		{[]byte("a=\xC3\xA9\r\nb=2"), EncodingAuto, 6},
+	} {
+		doc, err := LoadBytes(c.input, WithEncoding(c.encoding))
+		that.Nil(err)
+
+		pos, _ := doc.Position("b")
+		that.Equal(c.offset, pos.Offset, "encoding %v", c.encoding)
+	}
+}
+
+func TestPositionFile(t *testing.T) {
+	doc, _ := LoadFile("load_test.properties")
+	pos, _ := doc.Position("key")
+	assert.Equal(t, "load_test.properties:1", pos.String())
+}
@@ -0,0 +1,61 @@
+package properties
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LoadProfiles loads the base document like application.properties in the dir,
+// then overlays application-{profile}.properties for each active profile in order.
+//
+// The base is the file name without the .properties extension, like "application".
+// The latter profile takes precedence over the former ones and the base.
+// The missing profile files are skipped, but the base file is required.
+// The lines of the profile files are kept as the included lines, so Save writes the base document only.
+func LoadProfiles(dir, base string, profiles ...string) (*Doc, error) {
+	doc, err := LoadFile(filepath.Join(dir, base+".properties"))
+	if err != nil {
+		return nil, err
+	}
+
+	doc.profiles = make(map[string]string)
+
+	for _, profile := range profiles {
+		file := filepath.Join(dir, base+"-"+profile+".properties")
+
+		overlay, err := LoadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		doc.profiles[file] = profile
+
+		for e := overlay.lines.Front(); e != nil; e = e.Next() {
+			l := e.Value.(*line)
+			l.included = true
+
+			if elem := doc.lines.PushBack(l); l.isProperty() {
+				doc.props[l.key] = elem
+			}
+		}
+	}
+
+	return doc, nil
+}
+
+// ProfileOf tells which profile supplies the value of the key,
+// the profile is "" if the value comes from the base document.
+//
+// If the line is not exist, the exist is false.
+func (p Doc) ProfileOf(key string) (profile string, exist bool) {
+	pos, ok := p.Position(key)
+	if !ok {
+		return "", false
+	}
+
+	return p.profiles[pos.Source], true
+}
@@ -0,0 +1,50 @@
+package properties
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"application.properties":          "name=app\n# the port\nport=80\nlevel=info\n",
+		"application-dev.properties":      "port=8080\nlevel=debug\n",
+		"application-override.properties": "level=trace\n",
+	})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+
+	doc, err := LoadProfiles(dir, "application", "dev", "missing", "override")
+	that.Nil(err)
+	that.Equal(map[string]string{"name": "app", "port": "8080", "level": "trace"}, doc.Map())
+
+	profile, ok := doc.ProfileOf("port")
+	that.True(ok)
+	that.Equal("dev", profile)
+
+	profile, _ = doc.ProfileOf("level")
+	that.Equal("override", profile)
+
+	profile, _ = doc.ProfileOf("name")
+	that.Equal("", profile)
+
+	_, ok = doc.ProfileOf("NOT-EXIST")
+	that.False(ok)
+
+	pos, _ := doc.Position("port")
+	that.Equal(filepath.Join(dir, "application-dev.properties"), pos.Source)
+	that.Equal("name=app\n# the port\nport=80\nlevel=info\n", doc.String())
+
+	doc.Set("port", "90")
+	that.Equal("90", doc.Str("port"))
+	that.Equal("90", doc.Map()["port"])
+	that.Equal([]string{"90"}, doc.GetAll("port"))
+	that.Equal("name=app\n# the port\nport=90\nlevel=info\n", doc.String())
+
+	_, err = LoadProfiles(dir, "notexists", "dev")
+	that.NotNil(err)
+}
@@ -5,58 +5,30 @@ import "strconv"
 // StrOr retrieves the string value by key.
 // If the line is not exist, the def will be returned.
 func (p Doc) StrOr(key, def string) string {
-	if val, ok := p.Get(key); ok {
-		return val
-	}
-
-	return def
+	return strOr(p, key, def)
 }
 
 // IntOr retrieves the int value by key.
 // If the line is not exist, the def will be returned.
 func (p Doc) IntOr(key string, def int) int {
-	if val, ok := p.Get(key); ok {
-		if v, err := strconv.Atoi(val); err == nil {
-			return v
-		}
-	}
-
-	return def
+	return intOr(p, key, def)
 }
 
 // Int64Or retrieves the int64 value by key.
 // If the line is not exist, the def will be returned.
 func (p Doc) Int64Or(key string, def int64) int64 {
-	if val, ok := p.Get(key); ok {
-		if v, err := strconv.ParseInt(val, 10, 64); err == nil {
-			return v
-		}
-	}
-
-	return def
+	return int64Or(p, key, def)
 }
 
 // Uint64Or Same as Int64Or, but the return type is uint64.
 func (p Doc) Uint64Or(key string, def uint64) uint64 {
-	if val, ok := p.Get(key); ok {
-		if v, err := strconv.ParseUint(val, 10, 64); err == nil {
-			return v
-		}
-	}
-
-	return def
+	return uint64Or(p, key, def)
 }
 
 // Float64Or   retrieve the float64 value by key.
 // If the line is not exist, the def will be returned.
 func (p Doc) Float64Or(key string, def float64) float64 {
-	if val, ok := p.Get(key); ok {
-		if v, err := strconv.ParseFloat(val, 64); err == nil {
-			return v
-		}
-	}
-
-	return def
+	return float64Or(p, key, def)
 }
 
 // BoolOr   retrieve the bool value by key.
@@ -65,26 +37,14 @@ func (p Doc) Float64Or(key string, def float64) float64 {
 // This function mapping "0", "f", "F", "false", "FALSE", "False" as false.
 // If the line is not exist of can not map to value of bool,the def will be returned.
 func (p Doc) BoolOr(key string, def bool) bool {
-	if val, ok := p.Get(key); ok {
-		if v, err := strconv.ParseBool(val); err == nil {
-			return v
-		}
-	}
-
-	return def
+	return boolOr(p, key, def)
 }
 
 // ObjectOr maps the value of the key to any object.
 // The f is the customized mapping function.
 // Return def if the line is not exist of f have a error returned.
 func (p Doc) ObjectOr(key string, def interface{}, f func(k, v string) (interface{}, error)) interface{} {
-	if val, ok := p.Get(key); ok {
-		if v, err := f(key, val); err == nil {
-			return v
-		}
-	}
-
-	return def
+	return objectOr(p, key, def, f)
 }
 
 // Str same as StrOr but the def is "".
@@ -123,3 +83,78 @@ func (p Doc) Bool(key string) bool {
 func (p Doc) Object(key string, f func(k, v string) (interface{}, error)) interface{} {
 	return p.ObjectOr(key, nil, f)
 }
+
+// getter retrieves the values by key, it's implemented by Doc and Layered,
+// so they share the same typed getters.
+type getter interface {
+	Get(key string) (value string, exist bool)
+	Lookup(key string) (value string, exist bool, err error)
+}
+
+func strOr(p getter, key, def string) string {
+	if val, ok := p.Get(key); ok {
+		return val
+	}
+
+	return def
+}
+
+func intOr(p getter, key string, def int) int {
+	if val, ok := p.Get(key); ok {
+		if v, err := strconv.Atoi(val); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
+
+func int64Or(p getter, key string, def int64) int64 {
+	if val, ok := p.Get(key); ok {
+		if v, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
+
+func uint64Or(p getter, key string, def uint64) uint64 {
+	if val, ok := p.Get(key); ok {
+		if v, err := strconv.ParseUint(val, 10, 64); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
+
+func float64Or(p getter, key string, def float64) float64 {
+	if val, ok := p.Get(key); ok {
+		if v, err := strconv.ParseFloat(val, 64); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
+
+func boolOr(p getter, key string, def bool) bool {
+	if val, ok := p.Get(key); ok {
+		if v, err := strconv.ParseBool(val); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
+
+func objectOr(p getter, key string, def interface{}, f func(k, v string) (interface{}, error)) interface{} {
+	if val, ok := p.Get(key); ok {
+		if v, err := f(key, val); err == nil {
+			return v
+		}
+	}
+
+	return def
+}
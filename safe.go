@@ -0,0 +1,242 @@
+package properties
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// SafeDoc is a goroutine-safe properties document.
+//
+// The reads are lock-free on an immutable snapshot of the document,
+// the writes are serialized, each of them modifies a copy and swaps it in atomically.
+// So the writes are expensive for the large documents, batch them by Store if possible.
+type SafeDoc struct {
+	mu  sync.Mutex   //  串行化写操作
+	doc atomic.Value //  *Doc,当前的不可变快照
+}
+
+// NewSafeDoc creates a SafeDoc from a copy of the doc, or an empty one if the doc is nil.
+func NewSafeDoc(doc *Doc) *SafeDoc {
+	s := &SafeDoc{}
+	s.Store(doc)
+
+	return s
+}
+
+// Doc gives a snapshot of the current document, see Doc.Snapshot.
+//
+// The snapshot can be modified, which copies the lines first and doesn't affect the SafeDoc.
+func (s *SafeDoc) Doc() *Doc {
+	c := *s.load()
+	return &c
+}
+
+// load gives the current document, which must not be modified.
+func (s *SafeDoc) load() *Doc {
+	return s.doc.Load().(*Doc)
+}
+
+// store stores the document, which is marked as shared so that its snapshots copy the lines before modifying.
+func (s *SafeDoc) store(doc *Doc) {
+	doc.shared = true
+	s.doc.Store(doc)
+}
+
+// Store replaces the document by a copy of the doc, or an empty one if the doc is nil.
+func (s *SafeDoc) Store(doc *Doc) {
+	if doc == nil {
+		doc = New()
+	} else {
+		doc = doc.Clone()
+	}
+
+	s.mu.Lock()
+	s.store(doc)
+	s.mu.Unlock()
+}
+
+// modify applies f to a copy of the document, and swaps the copy in if f returns true.
+func (s *SafeDoc) modify(f func(doc *Doc) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := s.load().Clone()
+	if !f(doc) {
+		return false
+	}
+
+	s.store(doc)
+
+	return true
+}
+
+// Set sets the value of the key, see Doc.Set.
+func (s *SafeDoc) Set(key, value string) {
+	s.modify(func(doc *Doc) bool { doc.Set(key, value); return true })
+}
+
+// SetAll sets the values of the duplicate key, see Doc.SetAll.
+func (s *SafeDoc) SetAll(key string, values ...string) {
+	s.modify(func(doc *Doc) bool { doc.SetAll(key, values...); return true })
+}
+
+// Del deletes the key, see Doc.Del.
+func (s *SafeDoc) Del(key string) bool {
+	return s.modify(func(doc *Doc) bool { return doc.Del(key) })
+}
+
+// Comment appends comments for the special line, see Doc.Comment.
+func (s *SafeDoc) Comment(key, comments string) bool {
+	return s.modify(func(doc *Doc) bool { return doc.Comment(key, comments) })
+}
+
+// Uncomment removes all of the comments for the special line, see Doc.Uncomment.
+func (s *SafeDoc) Uncomment(key string) bool {
+	return s.modify(func(doc *Doc) bool { return doc.Uncomment(key) })
+}
+
+// SetExpansion enables or disables the expansion of ${...} references, see Doc.SetExpansion.
+func (s *SafeDoc) SetExpansion(enabled bool) {
+	s.modify(func(doc *Doc) bool { doc.SetExpansion(enabled); return true })
+}
+
+// Get retrieves the expanded value, see Doc.Get.
+func (s *SafeDoc) Get(key string) (value string, exist bool) {
+	return s.load().Get(key)
+}
+
+// GetRaw retrieves the value without expansion, see Doc.GetRaw.
+func (s *SafeDoc) GetRaw(key string) (value string, exist bool) {
+	return s.load().GetRaw(key)
+}
+
+// Lookup retrieves the expanded value, see Doc.Lookup.
+func (s *SafeDoc) Lookup(key string) (value string, exist bool, err error) {
+	return s.load().Lookup(key)
+}
+
+// MustGet returns the expanded value for the given key if exists or
+// panics otherwise.
+func (s *SafeDoc) MustGet(key string) (value string) {
+	return s.load().MustGet(key)
+}
+
+// GetAll retrieves all of the values of the duplicate key, see Doc.GetAll.
+func (s *SafeDoc) GetAll(key string) []string {
+	return s.load().GetAll(key)
+}
+
+// Position gives the position of the key, see Doc.Position.
+func (s *SafeDoc) Position(key string) (pos Position, exist bool) {
+	return s.load().Position(key)
+}
+
+// Map gets the map of properties.
+func (s *SafeDoc) Map() map[string]string {
+	return s.load().Map()
+}
+
+// Accept traverses every line of the snapshot, see Doc.Accept.
+func (s *SafeDoc) Accept(f func(typo byte, value, key string) bool) {
+	s.load().Accept(f)
+}
+
+// Foreach traverses all of the key-value pairs of the snapshot, see Doc.Foreach.
+func (s *SafeDoc) Foreach(f func(value, key string) bool) {
+	s.load().Foreach(f)
+}
+
+// Populate populates the expanded properties to the structure's field.
+func (s *SafeDoc) Populate(b interface{}, tag string) error {
+	return s.load().Populate(b, tag)
+}
+
+// String gives the string of the document.
+func (s *SafeDoc) String() string {
+	return s.load().String()
+}
+
+// Save saves the snapshot to the writer, see Doc.Save.
+func (s *SafeDoc) Save(writer io.Writer, options ...SaveOption) error {
+	return s.load().Save(writer, options...)
+}
+
+// ExportFile saves the snapshot to the file, see Doc.ExportFile.
+func (s *SafeDoc) ExportFile(file string, options ...SaveOption) error {
+	return s.load().ExportFile(file, options...)
+}
+
+// StrOr retrieves the string value by key.
+// If the line is not exist, the def will be returned.
+func (s *SafeDoc) StrOr(key, def string) string {
+	return s.load().StrOr(key, def)
+}
+
+// IntOr retrieves the int value by key.
+// If the line is not exist, the def will be returned.
+func (s *SafeDoc) IntOr(key string, def int) int {
+	return s.load().IntOr(key, def)
+}
+
+// Int64Or retrieves the int64 value by key.
+// If the line is not exist, the def will be returned.
+func (s *SafeDoc) Int64Or(key string, def int64) int64 {
+	return s.load().Int64Or(key, def)
+}
+
+// Uint64Or Same as Int64Or, but the return type is uint64.
+func (s *SafeDoc) Uint64Or(key string, def uint64) uint64 {
+	return s.load().Uint64Or(key, def)
+}
+
+// Float64Or retrieve the float64 value by key.
+// If the line is not exist, the def will be returned.
+func (s *SafeDoc) Float64Or(key string, def float64) float64 {
+	return s.load().Float64Or(key, def)
+}
+
+// BoolOr retrieve the bool value by key, see Doc.BoolOr.
+func (s *SafeDoc) BoolOr(key string, def bool) bool {
+	return s.load().BoolOr(key, def)
+}
+
+// ObjectOr maps the value of the key to any object, see Doc.ObjectOr.
+func (s *SafeDoc) ObjectOr(key string, def interface{}, f func(k, v string) (interface{}, error)) interface{} {
+	return s.load().ObjectOr(key, def, f)
+}
+
+// Str same as StrOr but the def is "".
+func (s *SafeDoc) Str(key string) string {
+	return s.StrOr(key, "")
+}
+
+// Int is same as IntOr but the def is 0 .
+func (s *SafeDoc) Int(key string) int {
+	return s.IntOr(key, 0)
+}
+
+// Int64 is same as Int64Or but the def is 0 .
+func (s *SafeDoc) Int64(key string) int64 {
+	return s.Int64Or(key, 0)
+}
+
+// Uint64 same as Uint64Or but the def is 0 .
+func (s *SafeDoc) Uint64(key string) uint64 {
+	return s.Uint64Or(key, 0)
+}
+
+// Float64 same as Float64Or but the def is 0.0 .
+func (s *SafeDoc) Float64(key string) float64 {
+	return s.Float64Or(key, 0.0)
+}
+
+// Bool same as BoolOr but the def is false.
+func (s *SafeDoc) Bool(key string) bool {
+	return s.BoolOr(key, false)
+}
+
+// Object is same as ObjectOr but the def is nil.
+func (s *SafeDoc) Object(key string, f func(k, v string) (interface{}, error)) interface{} {
+	return s.ObjectOr(key, nil, f)
+}
@@ -0,0 +1,72 @@
+package properties
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeDoc(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadString("# comment\na = 1\nb = ${a}\n")
+	s := NewSafeDoc(doc)
+
+	doc.Set("a", "changed")
+	that.Equal("1", s.Str("b"), "the doc is copied")
+
+	snapshot := s.Doc()
+
+	s.Set("c", "3")
+	that.True(s.Comment("c", "new"))
+	that.Equal(3, s.Int("c"))
+	that.Equal("# comment\na = 1\nb = ${a}\n#new\nc=3\n", s.String())
+	that.Equal("# comment\na = 1\nb = ${a}\n", snapshot.String(), "the snapshot is immutable")
+
+	snapshot.Set("a", "modified")
+	that.Equal("modified", snapshot.Str("a"))
+	that.Equal("1", s.Str("a"), "the modified snapshot doesn't affect the SafeDoc")
+
+	that.True(s.Uncomment("c"))
+	that.True(s.Del("c"))
+	that.False(s.Del("c"))
+	that.False(s.Comment("c", "x"))
+	that.Equal("# comment\na = 1\nb = ${a}\n", s.String())
+
+	s.Store(nil)
+	that.Equal("", s.String())
+}
+
+func TestSafeDocConcurrent(t *testing.T) {
+	s := NewSafeDoc(nil)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				s.Set("k"+strconv.Itoa(i), strconv.Itoa(j))
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				s.Int("k0")
+				s.Map()
+				s.Doc().Set("k0", "snapshot")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, map[string]string{"k0": "99", "k1": "99", "k2": "99", "k3": "99"}, s.Map())
+}
@@ -1,23 +1,36 @@
 package properties
 
 import (
-	"bufio"
 	"bytes"
-	"fmt"
+	"container/list"
 	"io"
-	"os"
 
 	"github.com/bingoohuang/gor"
 )
 
-// Populate populates the properties to the structure's field.
+// Populate populates the expanded properties to the structure's field.
 func (p Doc) Populate(b interface{}, tag string) error {
-	return gor.PopulateStruct(b, tag, func(filedName, tagValue string) (interface{}, bool) {
+	return populate(p, b, tag)
+}
+
+func populate(p getter, b interface{}, tag string) error {
+	var expandErr error
+
+	err := gor.PopulateStruct(b, tag, func(filedName, tagValue string) (interface{}, bool) {
 		return gor.TryFind(filedName, tagValue, func(name string) (interface{}, bool) {
-			value, ok := p.Get(name)
+			value, ok, err := p.Lookup(name)
+			if err != nil && expandErr == nil {
+				expandErr = err
+			}
+
 			return value, ok
 		})
 	})
+	if err != nil {
+		return err
+	}
+
+	return expandErr
 }
 
 // String gives the whole properties as a string
@@ -27,47 +40,156 @@ func (p Doc) String() string {
 	return s
 }
 
-// ExportFile saves the doc to file.
-func (p Doc) ExportFile(file string) error {
-	f, err := os.Create(file)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+// SaveOptions defines the options used to save the properties document.
+type SaveOptions struct {
+	// Encoding specifies the output encoding, default the encoding of the loaded document.
+	Encoding Encoding
+	// BOM specifies whether to write the BOM, default as the loaded document.
+	// UTF-16 without explicit byte order is always written with the BOM.
+	BOM bool
+	// Backups specifies how many backups ExportFile keeps, default 0.
+	// The replaced file is kept as file.bak, and the former backups are rotated to file.bak.1, file.bak.2 ...
+	Backups int
+}
 
-	w := bufio.NewWriter(f)
-	err = p.Save(w)
+// SaveOption defines the option function for saving.
+type SaveOption func(*SaveOptions)
 
-	if err != nil {
-		return err
+// WithOutputEncoding specifies the output encoding.
+func WithOutputEncoding(encoding Encoding) SaveOption {
+	return func(o *SaveOptions) { o.Encoding = encoding }
+}
+
+// WithBOM specifies whether to write the BOM.
+func WithBOM(bom bool) SaveOption {
+	return func(o *SaveOptions) { o.BOM = bom }
+}
+
+// WithBackups specifies how many backups ExportFile keeps.
+func WithBackups(n int) SaveOption {
+	return func(o *SaveOptions) { o.Backups = n }
+}
+
+func (p Doc) makeSaveOptions(options []SaveOption) *SaveOptions {
+	o := &SaveOptions{Encoding: p.encoding, BOM: p.bom}
+
+	for _, f := range options {
+		f(o)
 	}
 
-	w.Flush()
+	return o
+}
 
-	return nil
+// ExportFile saves the doc to file atomically.
+//
+// The doc is written to a temporary file in the same directory, which is synced and renamed over the file,
+// so a crash or a full disk never leaves a truncated file. The mode and the ownership of the file are kept.
+func (p Doc) ExportFile(file string, options ...SaveOption) error {
+	opts := p.makeSaveOptions(options)
+
+	return writeFileAtomic(file, opts.Backups, func(w io.Writer) error {
+		return p.Save(w, options...)
+	})
 }
 
-// Export saves the doc to file or stream.
+// Export saves the doc to a UTF-8 string.
 func (p Doc) Export() (string, error) {
 	buf := bytes.NewBufferString("")
-	err := p.Save(buf)
+	err := p.write(buf)
 
 	return buf.String(), err
 }
 
 // Save saves the doc to file or stream.
-func (p Doc) Save(writer io.Writer) error {
-	var err error
-
-	p.Accept(func(typo byte, value string, key string) bool {
-		if isProperty(typo) {
-			_, err = fmt.Fprintf(writer, "%s%c%s\n", key, typo, value)
-		} else {
-			_, err = fmt.Fprintln(writer, value)
-		}
+//
+// The lines loaded from the source and not modified yet are written back unchanged,
+// and the bytes are encoded in the same encoding as the source unless specified by options.
+func (p Doc) Save(writer io.Writer, options ...SaveOption) error {
+	opts := p.makeSaveOptions(options)
 
-		return nil == err
-	})
+	buf := bytes.NewBufferString("")
+	if err := p.write(buf); err != nil {
+		return err
+	}
+
+	b, err := encode(buf.String(), opts.Encoding, opts.BOM, p.dialect == DialectJava)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(b)
 
 	return err
 }
+
+// write writes the lines as UTF-8 text, the included lines are ignored.
+func (p Doc) write(writer io.Writer) error {
+	var last *list.Element
+
+	for e := p.lines.Back(); e != nil && last == nil; e = e.Prev() {
+		if !e.Value.(*line).included {
+			last = e
+		}
+	}
+
+	for e := p.lines.Front(); e != nil; e = e.Next() {
+		l := e.Value.(*line)
+		if l.included {
+			continue
+		}
+
+		//  只有原文档末尾没有换行时,最后一行才没有行尾
+		eol := l.eol
+		if eol == "" && (e != last || !p.noFinalEOL) {
+			eol = p.eol
+		}
+
+		if _, err := io.WriteString(writer, p.render(l)+eol); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render gives the text of the line without the line ending.
+//
+// The untouched lines are given as the raw text,
+// the modified property lines are rendered with their original layout.
+func (p Doc) render(l *line) string {
+	if l.raw != "" {
+		return l.raw
+	}
+
+	if !l.isProperty() {
+		return l.value
+	}
+
+	key, value := l.key, l.value
+	if p.dialect == DialectJava {
+		key, value = escapeJava(key, true), escapeJava(value, false)
+	}
+
+	sep := l.sep
+	if sep == "" {
+		sep = string(l.typo)
+	}
+
+	return l.indent + key + sep + value + l.trail
+}
+
+// errWriter writes the strings until the first error.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errWriter) write(ss ...string) {
+	for _, s := range ss {
+		if w.err != nil {
+			return
+		}
+
+		_, w.err = io.WriteString(w.w, s)
+	}
+}
@@ -129,6 +129,28 @@ func TestSaveFile(t *testing.T) {
 	assert.Nil(t, err)
 
 	nv, _ := ioutil.ReadFile("save_test.properties")
-	assert.Equal(t, string(nv), "key="+val+"\n")
+	assert.Equal(t, string(nv), "key="+val)
 	os.Remove("save_test.properties")
 }
+
+func TestSaveLossless(t *testing.T) {
+	src := "# comment\r\n  a  =  1  \r\n\t\r\nb:2\r\nc"
+
+	doc, _ := LoadString(src)
+	s, _ := doc.Export()
+	assert.Equal(t, src, s)
+
+	doc.Set("a", "10")
+	doc.Set("c", "3")
+	doc.Set("d", "4")
+	s, _ = doc.Export()
+	assert.Equal(t, "# comment\r\n  a  =  10  \r\n\t\r\nb:2\r\nc=3\r\nd=4", s)
+
+	java := "k1 \\\r\n   v1 \\\r\n   v2\nk2   v\n"
+	doc, _ = LoadString(java, WithDialect(DialectJava))
+	assert.Equal(t, "v1 v2", doc.Str("k1"))
+	assert.Equal(t, java, doc.String())
+
+	doc.Set("k2", "x")
+	assert.Equal(t, "k1 \\\r\n   v1 \\\r\n   v2\nk2   x\n", doc.String())
+}
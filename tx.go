@@ -0,0 +1,67 @@
+package properties
+
+// Tx is the transaction of Update, the edits are applied to a working copy of the document.
+type Tx struct {
+	doc *Doc
+}
+
+// Update applies the edits in f atomically.
+//
+// If f returns an error, all of the edits are rolled back and the error is returned.
+// Otherwise the edits are committed and the DiffEvents(except Same) are returned,
+// including the Commented events for the changed comments.
+func (p *Doc) Update(f func(tx *Tx) error) ([]DiffEvent, error) {
+	before := p.Snapshot()
+	tx := &Tx{doc: p.Snapshot()}
+
+	if err := f(tx); err != nil {
+		return nil, err
+	}
+
+	*p = *tx.doc
+
+	var events []DiffEvent
+
+	DiffWith(before, p, DiffOptions{Comments: true}, func(e DiffEvent) {
+		if e.ChangeType != Same {
+			events = append(events, e)
+		}
+	})
+
+	return events, nil
+}
+
+// Set sets the value of the key in the transaction, see Doc.Set.
+func (tx *Tx) Set(key, value string) {
+	tx.doc.Set(key, value)
+}
+
+// SetAll sets the values of the duplicate key in the transaction, see Doc.SetAll.
+func (tx *Tx) SetAll(key string, values ...string) {
+	tx.doc.SetAll(key, values...)
+}
+
+// Del deletes the key in the transaction, see Doc.Del.
+func (tx *Tx) Del(key string) bool {
+	return tx.doc.Del(key)
+}
+
+// Comment appends comments for the special line in the transaction, see Doc.Comment.
+func (tx *Tx) Comment(key, comments string) bool {
+	return tx.doc.Comment(key, comments)
+}
+
+// Uncomment removes all of the comments for the special line in the transaction, see Doc.Uncomment.
+func (tx *Tx) Uncomment(key string) bool {
+	return tx.doc.Uncomment(key)
+}
+
+// Get retrieves the expanded value with the edits in the transaction, see Doc.Get.
+func (tx *Tx) Get(key string) (value string, exist bool) {
+	return tx.doc.Get(key)
+}
+
+// GetRaw retrieves the value without expansion with the edits in the transaction, see Doc.GetRaw.
+func (tx *Tx) GetRaw(key string) (value string, exist bool) {
+	return tx.doc.GetRaw(key)
+}
@@ -0,0 +1,79 @@
+package properties
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# comment\na=1\nb=2\n"
+	doc, _ := LoadString(text)
+
+	events, err := doc.Update(func(tx *Tx) error {
+		tx.Set("a", "10")
+		tx.Del("b")
+		tx.Set("c", "3")
+		tx.Comment("c", "new")
+
+		v, _ := tx.Get("a")
+		that.Equal("10", v)
+
+		return nil
+	})
+
+	that.Nil(err)
+	that.Equal([]DiffEvent{
+		{ChangeType: Modified, Key: "a", LeftValue: "1", RightValue: "10"},
+		{ChangeType: Added, Key: "c", RightValue: "3"},
+		{ChangeType: Removed, Key: "b", LeftValue: "2"},
+	}, events)
+	that.Equal("# comment\na=10\n#new\nc=3\n", doc.String())
+}
+
+func TestUpdateRollback(t *testing.T) {
+	that := assert.New(t)
+
+	text := "# comment\na=1\nb=2\n"
+	doc, _ := LoadString(text)
+
+	events, err := doc.Update(func(tx *Tx) error {
+		tx.Set("a", "10")
+		tx.Uncomment("a")
+		tx.SetAll("b", "3", "4")
+
+		return errors.New("abort")
+	})
+
+	that.EqualError(err, "abort")
+	that.Nil(events)
+	that.Equal(text, doc.String())
+
+	events, err = doc.Update(func(tx *Tx) error { return nil })
+	that.Nil(err)
+	that.Nil(events)
+	that.Equal(text, doc.String())
+}
+
+func TestUpdateComments(t *testing.T) {
+	that := assert.New(t)
+
+	doc, _ := LoadString("# comment\na=1\nb=2\n")
+
+	events, err := doc.Update(func(tx *Tx) error {
+		tx.Uncomment("a")
+		tx.Comment("b", "new")
+
+		return nil
+	})
+
+	that.Nil(err)
+	that.Equal([]DiffEvent{
+		{ChangeType: Commented, Key: "a", LeftValue: "# comment"},
+		{ChangeType: Commented, Key: "b", RightValue: "#new"},
+	}, events)
+	that.Equal("a=1\n#new\nb=2\n", doc.String())
+}
@@ -0,0 +1,135 @@
+package properties
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// diffLine is a line of the unified diff.
+type diffLine struct {
+	op   byte //  ' ' 上下文, '-' 删除, '+' 新增
+	text string
+}
+
+// UnifiedDiff writes the events of DiffWith in the unified diff format, nothing is written if there is no change.
+//
+// Each property is a "key=value" line, the hunks have context lines of the Same events around the changes.
+// The Commented and Moved events are ignored.
+func UnifiedDiff(w io.Writer, events []DiffEvent, leftName, rightName string, context int) error {
+	lines := diffLines(events)
+	wr := &errWriter{w: w}
+
+	for start := 0; ; {
+		first, last := nextHunk(lines, start, context)
+		if first < 0 {
+			break
+		}
+
+		if start == 0 {
+			wr.write("--- ", leftName, "\n+++ ", rightName, "\n")
+		}
+
+		from, to := first-context, last+context+1
+		if from < 0 {
+			from = 0
+		}
+
+		if to > len(lines) {
+			to = len(lines)
+		}
+		wr.write(hunkHeader(lines, from, to))
+
+		for _, l := range lines[from:to] {
+			wr.write(string(l.op), l.text, "\n")
+		}
+
+		start = to
+	}
+
+	return wr.err
+}
+
+func diffLines(events []DiffEvent) []diffLine {
+	var lines []diffLine
+
+	text := func(key, value string) string {
+		return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(key + "=" + value)
+	}
+
+	for _, e := range events {
+		switch e.ChangeType {
+		case Same:
+			lines = append(lines, diffLine{op: ' ', text: text(e.Key, e.RightValue)})
+		case Modified:
+			lines = append(lines, diffLine{op: '-', text: text(e.Key, e.LeftValue)},
+				diffLine{op: '+', text: text(e.Key, e.RightValue)})
+		case Removed:
+			lines = append(lines, diffLine{op: '-', text: text(e.Key, e.LeftValue)})
+		case Added:
+			lines = append(lines, diffLine{op: '+', text: text(e.Key, e.RightValue)})
+		}
+	}
+
+	return lines
+}
+
+// nextHunk gives the first and the last changed lines of the next hunk from start,
+// the changes separated by at most 2*context unchanged lines are in the same hunk. The first is -1 if no more change.
+func nextHunk(lines []diffLine, start, context int) (first, last int) {
+	first = -1
+
+	for i := start; i < len(lines); i++ {
+		if lines[i].op == ' ' {
+			continue
+		}
+
+		if first >= 0 && i-last-1 > 2*context {
+			break
+		}
+
+		if first < 0 {
+			first = i
+		}
+
+		last = i
+	}
+
+	return first, last
+}
+
+// hunkHeader gives the "@@ -l,s +l,s @@" line of the hunk lines[from:to].
+func hunkHeader(lines []diffLine, from, to int) string {
+	var lstart, rstart, lcount, rcount int
+
+	for i, l := range lines[:to] {
+		inHunk := i >= from
+
+		if l.op != '+' {
+			if inHunk {
+				lcount++
+			} else {
+				lstart++
+			}
+		}
+
+		if l.op != '-' {
+			if inHunk {
+				rcount++
+			} else {
+				rstart++
+			}
+		}
+	}
+
+	//  空的范围从前一行开始计数
+	if lcount > 0 {
+		lstart++
+	}
+
+	if rcount > 0 {
+		rstart++
+	}
+
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", lstart, lcount, rstart, rcount)
+}
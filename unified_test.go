@@ -0,0 +1,44 @@
+package properties
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	that := assert.New(t)
+
+	l := "a=1\nb=2\nc=3\nd=4\ne=5\nf=6\ng=7\nh=8\n"
+	r := "a=10\nb=2\nc=3\nd=4\ne=5\nf=6\nh=8\ni=multi\\nline\n"
+
+	var buf bytes.Buffer
+
+	that.Nil(UnifiedDiff(&buf, diffEvents(l, r, DiffOptions{}), "staging", "prod", 1))
+	that.Equal("--- staging\n+++ prod\n"+
+		"@@ -1,2 +1,2 @@\n-a=1\n+a=10\n b=2\n"+
+		"@@ -6,3 +6,3 @@\n f=6\n-g=7\n h=8\n+i=multi\\nline\n", buf.String())
+
+	buf.Reset()
+	that.Nil(UnifiedDiff(&buf, diffEvents(l, r, DiffOptions{}), "staging", "prod", 3))
+	that.Equal("--- staging\n+++ prod\n"+
+		"@@ -1,8 +1,8 @@\n-a=1\n+a=10\n b=2\n c=3\n d=4\n e=5\n f=6\n-g=7\n h=8\n+i=multi\\nline\n", buf.String())
+
+	buf.Reset()
+	that.Nil(UnifiedDiff(&buf, diffEvents("a=1\n", "b=1\n", DiffOptions{}), "l", "r", 0))
+	that.Equal("--- l\n+++ r\n@@ -1,1 +1,1 @@\n+b=1\n-a=1\n", buf.String())
+
+	buf.Reset()
+	that.Nil(UnifiedDiff(&buf, diffEvents("a=1\n", "a=10\n", DiffOptions{}), "l", "r", 0))
+	that.Equal("--- l\n+++ r\n@@ -1,1 +1,1 @@\n-a=1\n+a=10\n", buf.String())
+
+	//  两处修改之间恰好有2*context行未修改
+	buf.Reset()
+	that.Nil(UnifiedDiff(&buf, diffEvents("a=1\nb=2\nc=3\nd=4\n", "a=10\nb=2\nc=3\nd=40\n", DiffOptions{}), "l", "r", 1))
+	that.Equal("--- l\n+++ r\n@@ -1,4 +1,4 @@\n-a=1\n+a=10\n b=2\n c=3\n-d=4\n+d=40\n", buf.String())
+
+	buf.Reset()
+	that.Nil(UnifiedDiff(&buf, diffEvents(l, l, DiffOptions{}), "l", "r", 3))
+	that.Equal("", buf.String())
+}
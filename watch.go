@@ -0,0 +1,211 @@
+package properties
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WatchOptions defines the options of Watch.
+type WatchOptions struct {
+	// Debounce is the quiet period before reloading, to merge the events of
+	// an editor's rename-and-replace save, default 100ms.
+	Debounce time.Duration
+	// PollInterval is the interval of the polling fallback, default 1s.
+	PollInterval time.Duration
+	// Polling forces the polling even if inotify is available.
+	Polling bool
+	// LoadOptions are used to reload the file.
+	LoadOptions []LoadOption
+	// OnError is called when the file can not be reloaded, the previous document is kept.
+	OnError func(error)
+}
+
+// Watcher watches a properties file and reloads it on change, see Watch.
+type Watcher struct {
+	path    string
+	opts    WatchOptions
+	f       func([]DiffEvent)
+	changes chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	closer  io.Closer
+	once    sync.Once //  保证只关闭一次
+	calling int32     //  正在调用f或者OnError时为1
+
+	mu  sync.Mutex
+	doc *Doc
+}
+
+// Watch loads the file, then reloads it when it changes and calls f with the
+// DiffEvents(except Same) against the previous document.
+//
+// The changes are detected by inotify on Linux with a polling fallback.
+// The file is watched by its directory, so the files deleted and recreated,
+// or replaced by rename, are still watched. Close the Watcher to stop watching.
+func Watch(path string, opts WatchOptions, f func([]DiffEvent)) (*Watcher, error) {
+	if opts.Debounce <= 0 {
+		opts.Debounce = 100 * time.Millisecond
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	doc, err := LoadFile(path, opts.LoadOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{path: path, opts: opts, f: f, doc: doc,
+		changes: make(chan struct{}, 1), stop: make(chan struct{}), done: make(chan struct{})}
+
+	if !opts.Polling {
+		w.closer, err = notify(filepath.Dir(path), filepath.Base(path), w.changed)
+	}
+
+	if opts.Polling || err != nil {
+		w.closer = w.poll()
+	}
+
+	go w.loop()
+
+	return w, nil
+}
+
+// Doc gives the latest loaded document.
+func (w *Watcher) Doc() *Doc {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.doc
+}
+
+// Close stops watching, no more callbacks are called after it returns.
+//
+// It's safe to call Close in the callbacks, but Close doesn't wait for the running callback to return.
+func (w *Watcher) Close() error {
+	err := errors.New("watcher already closed")
+
+	w.once.Do(func() {
+		close(w.stop)
+		err = w.closer.Close()
+	})
+
+	//  回调中调用时,等待loop退出会死锁
+	if atomic.LoadInt32(&w.calling) == 0 {
+		<-w.done
+	}
+
+	return err
+}
+
+// call calls f on the loop goroutine unless the Watcher is closed.
+func (w *Watcher) call(f func()) {
+	select {
+	case <-w.stop:
+		return
+	default:
+	}
+
+	atomic.StoreInt32(&w.calling, 1)
+	defer atomic.StoreInt32(&w.calling, 0)
+
+	f()
+}
+
+// changed signals a change of the file without blocking.
+func (w *Watcher) changed() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Watcher) loop() {
+	defer close(w.done)
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			timer.Stop()
+			return
+		case <-w.changes:
+			timer.Reset(w.opts.Debounce)
+		case <-timer.C:
+			w.reload()
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	doc, err := LoadFile(w.path, w.opts.LoadOptions...)
+	if err != nil {
+		//  文件被删除时等待重新创建
+		if !os.IsNotExist(err) && w.opts.OnError != nil {
+			w.call(func() { w.opts.OnError(err) })
+		}
+
+		return
+	}
+
+	w.mu.Lock()
+	prev := w.doc
+	w.doc = doc
+	w.mu.Unlock()
+
+	if events := changes(prev, doc); len(events) > 0 {
+		w.call(func() { w.f(events) })
+	}
+}
+
+// poll detects the changes by the modification time and size of the file.
+func (w *Watcher) poll() io.Closer {
+	stop := make(chan struct{})
+	last := fileStamp(w.path)
+
+	go func() {
+		ticker := time.NewTicker(w.opts.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if stamp := fileStamp(w.path); stamp != last {
+					last = stamp
+					w.changed()
+				}
+			}
+		}
+	}()
+
+	return closerFunc(func() error { close(stop); return nil })
+}
+
+type stamp struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func fileStamp(path string) stamp {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+
+	return stamp{exists: true, size: fi.Size(), modTime: fi.ModTime()}
+}
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
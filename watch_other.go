@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package properties
+
+import (
+	"errors"
+	"io"
+)
+
+// notify is not supported on this platform, the polling is used instead.
+func notify(dir, name string, changed func()) (io.Closer, error) {
+	return nil, errors.New("file notification not supported")
+}
@@ -0,0 +1,140 @@
+package properties
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func waitEvents(t *testing.T, ch <-chan []DiffEvent) []DiffEvent {
+	select {
+	case events := <-ch:
+		return events
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the change events")
+		return nil
+	}
+}
+
+func testWatch(t *testing.T, opts WatchOptions) {
+	dir := writeFiles(t, map[string]string{"app.properties": "a=1\nb=2\n"})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	file := filepath.Join(dir, "app.properties")
+	ch := make(chan []DiffEvent, 10)
+
+	w, err := Watch(file, opts, func(events []DiffEvent) { ch <- events })
+	that.Nil(err)
+
+	defer w.Close()
+
+	that.Equal("1", w.Doc().Str("a"))
+
+	//  直接修改
+	that.Nil(ioutil.WriteFile(file, []byte("a=10\nb=2\n"), 0644))
+	that.Equal([]DiffEvent{{ChangeType: Modified, Key: "a", LeftValue: "1", RightValue: "10"}}, waitEvents(t, ch))
+	that.Equal("10", w.Doc().Str("a"))
+
+	//  编辑器的重命名替换
+	tmp := filepath.Join(dir, ".app.properties.swp")
+	that.Nil(ioutil.WriteFile(tmp, []byte("a=10\nb=2\nc=3\n"), 0644))
+	that.Nil(os.Rename(tmp, file))
+	that.Equal([]DiffEvent{{ChangeType: Added, Key: "c", RightValue: "3"}}, waitEvents(t, ch))
+
+	//  删除后重新创建
+	that.Nil(os.Remove(file))
+	time.Sleep(4 * opts.Debounce)
+	that.Nil(ioutil.WriteFile(file, []byte("a=10\nc=3\n"), 0644))
+	that.Equal([]DiffEvent{{ChangeType: Removed, Key: "b", LeftValue: "2"}}, waitEvents(t, ch))
+
+	//  内容不变时不回调
+	that.Nil(ioutil.WriteFile(file, []byte("a=10\n\nc=3\n"), 0644))
+	select {
+	case events := <-ch:
+		t.Errorf("unexpected events %v", events)
+	case <-time.After(5 * opts.Debounce):
+	}
+
+	that.Nil(w.Close())
+	that.NotNil(w.Close())
+}
+
+func TestWatch(t *testing.T) {
+	testWatch(t, WatchOptions{Debounce: 50 * time.Millisecond, PollInterval: 20 * time.Millisecond})
+}
+
+func TestWatchPolling(t *testing.T) {
+	testWatch(t, WatchOptions{Debounce: 50 * time.Millisecond, PollInterval: 20 * time.Millisecond, Polling: true})
+}
+
+func TestWatchClose(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.properties": "a=1\n"})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	file := filepath.Join(dir, "app.properties")
+	closed := make(chan error, 1)
+
+	var w *Watcher
+
+	w, err := Watch(file, WatchOptions{Debounce: 20 * time.Millisecond, PollInterval: 20 * time.Millisecond},
+		func([]DiffEvent) { closed <- w.Close() })
+	that.Nil(err)
+
+	that.Nil(ioutil.WriteFile(file, []byte("a=2\n"), 0644))
+
+	select {
+	case err := <-closed:
+		that.Nil(err, "close in the callback")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the close in the callback")
+	}
+
+	w, err = Watch(file, WatchOptions{}, func([]DiffEvent) {})
+	that.Nil(err)
+
+	errs := make(chan error, 2)
+
+	for i := 0; i < 2; i++ {
+		go func() { errs <- w.Close() }()
+	}
+
+	first, second := <-errs, <-errs
+	that.True(first == nil && second != nil || first != nil && second == nil, "close concurrently")
+}
+
+func TestWatchError(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"app.properties": "a=1\n"})
+	defer os.RemoveAll(dir)
+
+	that := assert.New(t)
+	file := filepath.Join(dir, "app.properties")
+
+	_, err := Watch(filepath.Join(dir, "missing.properties"), WatchOptions{}, func([]DiffEvent) {})
+	that.True(os.IsNotExist(err))
+
+	errs := make(chan error, 10)
+	opts := WatchOptions{Debounce: 20 * time.Millisecond, OnError: func(err error) { errs <- err },
+		LoadOptions: []LoadOption{WithDialect(DialectJava), WithParseMode(ParseStrict)}}
+
+	w, err := Watch(file, opts, func([]DiffEvent) {})
+	that.Nil(err)
+
+	defer w.Close()
+
+	that.Nil(ioutil.WriteFile(file, []byte("a=\\u12\n"), 0644))
+
+	select {
+	case err := <-errs:
+		that.NotNil(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for the error")
+	}
+
+	that.Equal("1", w.Doc().Str("a"))
+}
@@ -0,0 +1,181 @@
+package properties
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	xmlHeader  = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`
+	xmlDoctype = `<!DOCTYPE properties SYSTEM "http://java.sun.com/dtd/properties.dtd">`
+)
+
+// LoadXMLFile creates the properties document from a file in the java XML properties format.
+func LoadXMLFile(file string) (doc *Doc, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return LoadXML(f)
+}
+
+// LoadXML creates the properties document from a stream in the java XML properties format,
+// which is defined by http://java.sun.com/dtd/properties.dtd and written by Properties.storeToXML.
+//
+// The <comment> element becomes the leading comment lines separated by an empty line,
+// the XML comments become the comment lines of the following entry,
+// and each <entry key=""> becomes a property line.
+// The document is in the DialectJava, so it can be saved as a text properties file safely.
+func LoadXML(reader io.Reader) (doc *Doc, err error) {
+	doc = New()
+	doc.dialect = DialectJava
+
+	decoder := xml.NewDecoder(reader)
+	inProps := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return doc, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !inProps {
+				if t.Name.Local != "properties" {
+					return nil, errors.New("the root element should be <properties>")
+				}
+
+				inProps = true
+
+				continue
+			}
+
+			if err := doc.parseXMLElement(decoder, &t); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			inProps = false
+		case xml.Comment:
+			if inProps {
+				doc.appendComments(string(t))
+			}
+		}
+	}
+}
+
+// parseXMLElement parses the <comment> and <entry> elements.
+func (p *Doc) parseXMLElement(decoder *xml.Decoder, t *xml.StartElement) error {
+	switch t.Name.Local {
+	case "comment":
+		var text string
+		if err := decoder.DecodeElement(&text, t); err != nil {
+			return err
+		}
+
+		p.appendComments(text)
+		p.lines.PushBack(&line{typo: ' ', value: ""})
+	case "entry":
+		var entry struct {
+			Key   string `xml:"key,attr"`
+			Value string `xml:",chardata"`
+		}
+
+		if err := decoder.DecodeElement(&entry, t); err != nil {
+			return err
+		}
+
+		p.Set(entry.Key, entry.Value)
+	default:
+		return decoder.Skip()
+	}
+
+	return nil
+}
+
+// appendComments appends the comment lines for each line of the text.
+func (p *Doc) appendComments(text string) {
+	scanner := bufio.NewScanner(strings.NewReader(strings.Trim(text, "\r\n")))
+	for scanner.Scan() {
+		p.lines.PushBack(&line{typo: '#', value: "#" + scanner.Text()})
+	}
+}
+
+// SaveXML saves the doc to a stream in the java XML properties format.
+//
+// The leading comment lines not attached to a property are written as the <comment> element,
+// the other comment lines are written as XML comments, and the empty lines are dropped.
+func (p Doc) SaveXML(writer io.Writer) error {
+	w := &errWriter{w: writer}
+	w.write(xmlHeader, "\n", xmlDoctype, "\n<properties>\n")
+
+	e := p.lines.Front()
+
+	var header []string
+
+	for ; e != nil && isComment(e.Value.(*line).typo); e = e.Next() {
+		header = append(header, escapeXML(commentText(e.Value.(*line).value)))
+	}
+
+	if e == nil || e.Value.(*line).typo == ' ' {
+		if len(header) > 0 {
+			w.write("<comment>", strings.Join(header, "\n"), "</comment>\n")
+		}
+	} else {
+		e = p.lines.Front()
+	}
+
+	for ; e != nil; e = e.Next() {
+		l := e.Value.(*line)
+
+		switch {
+		case l.included: //  包含的行在各自的文件中
+		case l.isProperty():
+			w.write(`<entry key="`, escapeXML(l.key), `">`, escapeXML(l.value), "</entry>\n")
+		case isComment(l.typo):
+			w.write("<!--", escapeXMLComment(commentText(l.value)), "-->\n")
+		}
+	}
+
+	w.write("</properties>\n")
+
+	return w.err
+}
+
+// commentText gives the text of the comment line without the leading '#' or '!'.
+func commentText(value string) string {
+	return strings.TrimLeft(value, " \t\f")[1:]
+}
+
+// escapeXMLComment breaks the "--" which is not allowed in the XML comments,
+// include the one formed by a trailing '-' and the closing "-->".
+func escapeXMLComment(s string) string {
+	for strings.Contains(s, "--") {
+		s = strings.Replace(s, "--", "- -", -1)
+	}
+
+	if strings.HasSuffix(s, "-") {
+		s += " "
+	}
+
+	return s
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+
+	_ = xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
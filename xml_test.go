@@ -0,0 +1,55 @@
+package properties
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const xmlProps = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<!DOCTYPE properties SYSTEM "http://java.sun.com/dtd/properties.dtd">
+<properties>
+<comment>header1
+header2</comment>
+<!--for a-->
+<entry key="a">1 &lt; 2</entry>
+<entry key="b c">x&#xA;y</entry>
+</properties>
+`
+
+func TestLoadXML(t *testing.T) {
+	that := assert.New(t)
+
+	doc, err := LoadXML(bytes.NewBufferString(xmlProps))
+	that.Nil(err)
+	that.Equal(map[string]string{"a": "1 < 2", "b c": "x\ny"}, doc.Map())
+	that.Equal("#header1\n#header2\n\n#for a\na=1 < 2\nb\\ c=x\\ny\n", doc.String())
+
+	var buf bytes.Buffer
+	that.Nil(doc.SaveXML(&buf))
+	that.Equal(xmlProps, buf.String())
+
+	_, err = LoadXML(bytes.NewBufferString("<foo/>"))
+	that.NotNil(err)
+
+	_, err = LoadXML(bytes.NewBufferString("<properties><entry>"))
+	that.NotNil(err)
+}
+
+func TestSaveXML(t *testing.T) {
+	doc, _ := LoadString("#attached -- comment\na=1\n\n!tail\n#see a-\n#x---y")
+
+	var buf bytes.Buffer
+	assert.Nil(t, doc.SaveXML(&buf))
+	assert.Equal(t, xmlHeader+"\n"+xmlDoctype+"\n<properties>\n"+
+		"<!--attached - - comment-->\n<entry key=\"a\">1</entry>\n<!--tail-->\n<!--see a- -->\n<!--x- - -y-->\n"+
+		"</properties>\n", buf.String())
+
+	reload, err := LoadXML(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, doc.Map(), reload.Map())
+
+	_, err = LoadXMLFile("notexists.xml")
+	assert.NotNil(t, err)
+}